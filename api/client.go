@@ -0,0 +1,191 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client 는 검색기 서버의 HTTP API 클라이언트입니다.
+type Client struct {
+	BaseURL    string // 예: http://127.0.0.1:4412
+	HTTPClient *http.Client
+}
+
+// NewClient 함수는 baseURL 서버에 요청하는 클라이언트를 만듭니다.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: time.Second * 10},
+	}
+}
+
+// decode 함수는 응답을 v로 읽습니다. 2xx가 아니면 *Error를 반환합니다.
+func decode(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		var e ErrorResponse
+		if json.Unmarshal(body, &e) == nil && e.Error.Code != "" {
+			return &e.Error
+		}
+		return &Error{Status: resp.StatusCode, Code: "http", Message: strings.TrimSpace(string(body))}
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *Client) post(path string, request, v interface{}) error {
+	var b bytes.Buffer
+	if err := json.NewEncoder(&b).Encode(request); err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Post(c.BaseURL+path, "application/json", &b)
+	if err != nil {
+		return err
+	}
+	return decode(resp, v)
+}
+
+func (c *Client) get(path string, q url.Values, v interface{}) error {
+	u := c.BaseURL + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	resp, err := c.HTTPClient.Get(u)
+	if err != nil {
+		return err
+	}
+	return decode(resp, v)
+}
+
+// Rank 함수는 캐릭터의 최근, 최고 기록을 조회합니다. 기록이 없으면 Ok가 거짓인 응답을 반환합니다.
+func (c *Client) Rank(world, typeid int, name string) (*RankResponse, error) {
+	var ret RankResponse
+	if err := c.post("/getrank", CharacterRequest{world, typeid, name}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Character 함수는 GET /api/v1/characters 로 캐릭터를 조회합니다. world는 서버 번호 또는 이름입니다.
+// 기록이 없으면 Code가 unknown_character인 *Error를 반환합니다.
+func (c *Client) Character(world string, typeid int, name string) (*RankResponse, error) {
+	q := url.Values{}
+	if typeid != 0 {
+		q.Set("type", strconv.Itoa(typeid))
+	}
+	var ret RankResponse
+	path := "/api/v1/characters/" + url.PathEscape(world) + "/" + url.PathEscape(name)
+	if err := c.get(path, q, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Worlds 함수는 서버가 수집하는 서버와 카테고리 목록을 조회합니다.
+func (c *Client) Worlds() (*WorldsResponse, error) {
+	var ret WorldsResponse
+	if err := c.get("/api/v1/worlds", nil, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// History 함수는 캐릭터의 주별 기록을 조회합니다.
+func (c *Client) History(world, typeid int, name string) (*HistoryResponse, error) {
+	var ret HistoryResponse
+	if err := c.post("/history", CharacterRequest{world, typeid, name}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Compare 함수는 여러 캐릭터의 주별 기록을 나란히 비교합니다. targets의 World와 Name만 사용합니다.
+func (c *Client) Compare(typeid int, targets []CompareTarget) (*CompareResponse, error) {
+	q := url.Values{}
+	if typeid != 0 {
+		q.Set("type", strconv.Itoa(typeid))
+	}
+	for _, t := range targets {
+		q.Add("c", strconv.Itoa(t.World)+":"+t.Name)
+	}
+	var ret CompareResponse
+	if err := c.get("/compare", q, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// LeaderboardQuery 는 순위표 조회 조건입니다. 0 또는 빈 값은 서버 기본값을 사용합니다.
+type LeaderboardQuery struct {
+	World int // 0이면 모든 서버
+	Type  int
+	Week  string // 비어 있으면 전체 기간 최고 기록
+	Job   string
+	N     int
+}
+
+// Leaderboard 함수는 순위표를 조회합니다.
+func (c *Client) Leaderboard(query LeaderboardQuery) (*LeaderboardResponse, error) {
+	q := url.Values{}
+	if query.World != 0 {
+		q.Set("world", strconv.Itoa(query.World))
+	}
+	if query.Type != 0 {
+		q.Set("type", strconv.Itoa(query.Type))
+	}
+	if query.Week != "" {
+		q.Set("week", query.Week)
+	}
+	if query.Job != "" {
+		q.Set("job", query.Job)
+	}
+	if query.N != 0 {
+		q.Set("n", strconv.Itoa(query.N))
+	}
+	var ret LeaderboardResponse
+	if err := c.get("/leaderboard", q, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// Search 함수는 모든 서버에서 닉네임을 검색합니다. typeid, n이 0이면 서버 기본값을 사용합니다.
+func (c *Client) Search(query string, typeid, n int) (*SearchResponse, error) {
+	q := url.Values{"q": {query}}
+	if typeid != 0 {
+		q.Set("type", strconv.Itoa(typeid))
+	}
+	if n != 0 {
+		q.Set("n", strconv.Itoa(n))
+	}
+	var ret SearchResponse
+	if err := c.get("/search", q, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+// JobStats 함수는 세부 직업별 통계를 조회합니다. world가 0이면 모든 서버를 합칩니다.
+func (c *Client) JobStats(world, typeid int, week string) (*JobStatsResponse, error) {
+	q := url.Values{}
+	if world != 0 {
+		q.Set("world", strconv.Itoa(world))
+	}
+	if typeid != 0 {
+		q.Set("type", strconv.Itoa(typeid))
+	}
+	if week != "" {
+		q.Set("week", week)
+	}
+	var ret JobStatsResponse
+	if err := c.get("/stats/jobs", q, &ret); err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
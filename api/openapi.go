@@ -0,0 +1,329 @@
+package api
+
+// OpenAPI 는 서버가 /openapi.json 으로 제공하는 OpenAPI 3.0 문서입니다.
+// 이 패키지의 형식을 바꾸면 함께 고쳐야 합니다.
+const OpenAPI = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "무릉도장 전적 검색기 API",
+    "version": "1"
+  },
+  "paths": {
+    "/getrank": {
+      "post": {
+        "summary": "캐릭터의 최근, 최고 기록과 순위 정보",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/CharacterRequest"}}}},
+        "responses": {
+          "200": {"description": "기록이 없으면 Ok가 false입니다.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/RankResponse"}}}}
+        }
+      }
+    },
+    "/api/v1/characters/{world}/{name}": {
+      "get": {
+        "summary": "캐릭터의 최근, 최고 기록과 순위 정보",
+        "parameters": [
+          {"name": "world", "in": "path", "required": true, "description": "서버 번호 또는 이름", "schema": {"type": "string"}},
+          {"name": "name", "in": "path", "required": true, "schema": {"type": "string"}},
+          {"name": "type", "in": "query", "description": "카테고리(cateType). 생략하면 기본 카테고리", "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/RankResponse"}}}},
+          "400": {"$ref": "#/components/responses/Error"},
+          "404": {"$ref": "#/components/responses/Error"},
+          "500": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/v1/worlds": {
+      "get": {
+        "summary": "수집 중인 서버와 카테고리 목록",
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/WorldsResponse"}}}}
+        }
+      }
+    },
+    "/history": {
+      "post": {
+        "summary": "캐릭터의 주별 기록",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/CharacterRequest"}}}},
+        "responses": {
+          "200": {"description": "기록이 없으면 Ok가 false입니다.", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/HistoryResponse"}}}}
+        }
+      }
+    },
+    "/history.svg": {
+      "get": {
+        "summary": "캐릭터의 주별 층수, 소요 시간 추이 그래프",
+        "parameters": [
+          {"name": "world", "in": "query", "required": true, "description": "서버 번호 또는 이름", "schema": {"type": "string"}},
+          {"name": "type", "in": "query", "schema": {"type": "integer"}},
+          {"name": "name", "in": "query", "required": true, "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "OK", "content": {"image/svg+xml": {"schema": {"type": "string"}}}},
+          "404": {"description": "알 수 없는 서버이거나 기록이 없습니다."}
+        }
+      }
+    },
+    "/compare": {
+      "get": {
+        "summary": "여러 캐릭터의 주별 기록 비교",
+        "parameters": [
+          {"name": "c", "in": "query", "required": true, "description": "서버:닉네임. 서버는 번호 또는 이름이며, 2~10번 반복합니다.", "schema": {"type": "array", "items": {"type": "string"}}, "style": "form", "explode": true},
+          {"name": "type", "in": "query", "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/CompareResponse"}}}},
+          "400": {"description": "잘못된 파라미터"}
+        }
+      }
+    },
+    "/leaderboard": {
+      "get": {
+        "summary": "서버별 순위표",
+        "parameters": [
+          {"name": "world", "in": "query", "description": "생략하면 모든 서버", "schema": {"type": "integer"}},
+          {"name": "type", "in": "query", "schema": {"type": "integer"}},
+          {"name": "week", "in": "query", "description": "예: 2018-W09. 생략하거나 all이면 전체 기간 최고 기록", "schema": {"type": "string"}},
+          {"name": "job", "in": "query", "description": "직업군 또는 세부직업", "schema": {"type": "string"}},
+          {"name": "n", "in": "query", "description": "서버별 최대 인원 (기본 100, 최대 1000)", "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/LeaderboardResponse"}}}},
+          "400": {"description": "잘못된 파라미터"}
+        }
+      }
+    },
+    "/search": {
+      "get": {
+        "summary": "모든 서버에서 닉네임 검색",
+        "parameters": [
+          {"name": "q", "in": "query", "required": true, "schema": {"type": "string"}},
+          {"name": "type", "in": "query", "schema": {"type": "integer"}},
+          {"name": "n", "in": "query", "description": "최대 결과 수 (기본 20, 최대 100)", "schema": {"type": "integer"}}
+        ],
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SearchResponse"}}}},
+          "400": {"description": "잘못된 파라미터"}
+        }
+      }
+    },
+    "/stats/jobs": {
+      "get": {
+        "summary": "세부 직업별 통계",
+        "parameters": [
+          {"name": "world", "in": "query", "description": "생략하면 모든 서버", "schema": {"type": "integer"}},
+          {"name": "type", "in": "query", "schema": {"type": "integer"}},
+          {"name": "week", "in": "query", "description": "예: 2018-W09. 생략하거나 all이면 전체 기간 최고 기록", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "OK", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/JobStatsResponse"}}}},
+          "400": {"description": "잘못된 파라미터"}
+        }
+      }
+    }
+  },
+  "components": {
+    "responses": {
+      "Error": {"description": "오류", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ErrorResponse"}}}}
+    },
+    "schemas": {
+      "Record": {
+        "type": "object",
+        "properties": {
+          "rank": {"type": "string"},
+          "move": {"type": "string"},
+          "icon": {"type": "string"},
+          "nick": {"type": "string"},
+          "job": {"type": "string"},
+          "detail_job": {"type": "string"},
+          "level": {"type": "integer"},
+          "exp": {"type": "integer"},
+          "popular": {"type": "integer"},
+          "floor": {"type": "string", "description": "예: 61층"},
+          "duration": {"type": "string", "description": "예: 13분 2초"},
+          "guild_worldid": {"type": "string"},
+          "second": {"type": "integer"},
+          "minute": {"type": "integer"},
+          "world": {"type": "integer"},
+          "rawfloor": {"type": "integer"},
+          "type": {"type": "integer"},
+          "checkedtime": {"type": "integer", "description": "달성 시각(유닉스 시각)"}
+        }
+      },
+      "CharacterRequest": {
+        "type": "object",
+        "properties": {
+          "World": {"type": "integer"},
+          "Type": {"type": "integer"},
+          "Name": {"type": "string"}
+        }
+      },
+      "FormerName": {
+        "type": "object",
+        "properties": {
+          "Name": {"type": "string"},
+          "World": {"type": "integer"},
+          "WorldName": {"type": "string"},
+          "Until": {"type": "string", "description": "마지막으로 랭킹에 오른 주"}
+        }
+      },
+      "RankContext": {
+        "type": "object",
+        "properties": {
+          "Week": {"type": "string"},
+          "Rank": {"type": "integer"},
+          "Total": {"type": "integer"},
+          "Percentile": {"type": "number"},
+          "TopPercent": {"type": "number"},
+          "SameFloor": {"type": "integer"},
+          "NextFloor": {"type": "integer"},
+          "FloorGap": {"type": "integer"}
+        }
+      },
+      "RankResponse": {
+        "type": "object",
+        "properties": {
+          "Ok": {"type": "boolean"},
+          "World": {"type": "integer"},
+          "WorldName": {"type": "string"},
+          "Type": {"type": "integer"},
+          "ID": {"type": "integer"},
+          "Rank": {"$ref": "#/components/schemas/Record"},
+          "MRank": {"$ref": "#/components/schemas/Record"},
+          "FormerNames": {"type": "array", "items": {"$ref": "#/components/schemas/FormerName"}},
+          "Weekly": {"$ref": "#/components/schemas/RankContext"},
+          "AllTime": {"$ref": "#/components/schemas/RankContext"},
+          "Start": {"type": "integer"},
+          "End": {"type": "integer"}
+        }
+      },
+      "HistoryResponse": {
+        "type": "object",
+        "properties": {
+          "Ok": {"type": "boolean"},
+          "History": {"type": "array", "items": {"$ref": "#/components/schemas/Record"}},
+          "Start": {"type": "integer"},
+          "End": {"type": "integer"}
+        }
+      },
+      "Leaderboard": {
+        "type": "object",
+        "properties": {
+          "World": {"type": "integer"},
+          "Name": {"type": "string"},
+          "Ranks": {"type": "array", "items": {"$ref": "#/components/schemas/Record"}}
+        }
+      },
+      "LeaderboardResponse": {
+        "type": "object",
+        "properties": {
+          "Ok": {"type": "boolean"},
+          "Type": {"type": "integer"},
+          "Week": {"type": "string"},
+          "Worlds": {"type": "array", "items": {"$ref": "#/components/schemas/Leaderboard"}}
+        }
+      },
+      "SearchCandidate": {
+        "type": "object",
+        "properties": {
+          "World": {"type": "integer"},
+          "WorldName": {"type": "string"},
+          "Name": {"type": "string"},
+          "Job": {"type": "string"},
+          "DetailJob": {"type": "string"},
+          "Floor": {"type": "integer"},
+          "Duration": {"type": "string"},
+          "Distance": {"type": "integer"}
+        }
+      },
+      "SearchResponse": {
+        "type": "object",
+        "properties": {
+          "Ok": {"type": "boolean"},
+          "Candidates": {"type": "array", "items": {"$ref": "#/components/schemas/SearchCandidate"}}
+        }
+      },
+      "JobStats": {
+        "type": "object",
+        "properties": {
+          "Job": {"type": "string"},
+          "DetailJob": {"type": "string"},
+          "Count": {"type": "integer"},
+          "MedianFloor": {"type": "integer"},
+          "P25Floor": {"type": "integer"},
+          "P75Floor": {"type": "integer"},
+          "P90Floor": {"type": "integer"},
+          "MedianSec": {"type": "integer"},
+          "Best": {"$ref": "#/components/schemas/Record"}
+        }
+      },
+      "JobStatsResponse": {
+        "type": "object",
+        "properties": {
+          "Ok": {"type": "boolean"},
+          "World": {"type": "integer"},
+          "Type": {"type": "integer"},
+          "Week": {"type": "string"},
+          "Total": {"type": "integer"},
+          "Classes": {"type": "array", "items": {"$ref": "#/components/schemas/JobStats"}}
+        }
+      },
+      "CompareTarget": {
+        "type": "object",
+        "properties": {
+          "World": {"type": "integer"},
+          "WorldName": {"type": "string"},
+          "Name": {"type": "string"},
+          "Found": {"type": "boolean"},
+          "Wins": {"type": "integer", "description": "가장 좋은 기록을 낸 주의 수"}
+        }
+      },
+      "CompareWeek": {
+        "type": "object",
+        "properties": {
+          "Week": {"type": "string"},
+          "Records": {"type": "array", "description": "Characters와 같은 순서. 기록이 없으면 null", "items": {"$ref": "#/components/schemas/Record"}},
+          "Best": {"type": "array", "description": "가장 좋은 기록을 낸 캐릭터의 위치", "items": {"type": "integer"}}
+        }
+      },
+      "CompareResponse": {
+        "type": "object",
+        "properties": {
+          "Ok": {"type": "boolean"},
+          "Type": {"type": "integer"},
+          "Characters": {"type": "array", "items": {"$ref": "#/components/schemas/CompareTarget"}},
+          "Weeks": {"type": "array", "items": {"$ref": "#/components/schemas/CompareWeek"}}
+        }
+      },
+      "NamedID": {
+        "type": "object",
+        "properties": {
+          "ID": {"type": "integer"},
+          "Name": {"type": "string"}
+        }
+      },
+      "WorldsResponse": {
+        "type": "object",
+        "properties": {
+          "Worlds": {"type": "array", "items": {"$ref": "#/components/schemas/NamedID"}},
+          "Categories": {"type": "array", "items": {"$ref": "#/components/schemas/NamedID"}}
+        }
+      },
+      "ErrorResponse": {
+        "type": "object",
+        "properties": {
+          "Error": {
+            "type": "object",
+            "properties": {
+              "Status": {"type": "integer"},
+              "Code": {"type": "string"},
+              "Message": {"type": "string"}
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
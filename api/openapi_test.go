@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type schema struct {
+	Properties map[string]schema
+}
+
+// filled 함수는 모든 필드를 0이 아닌 값으로 채운 t 형식의 값을 반환합니다. omitempty 필드도 JSON에 나타나게 합니다.
+func filled(t reflect.Type) reflect.Value {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Ptr:
+		v.Set(filled(t.Elem()).Addr())
+	case reflect.Slice:
+		v.Set(reflect.Append(v, filled(t.Elem())))
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" {
+				v.Field(i).Set(filled(t.Field(i).Type))
+			}
+		}
+	}
+	return v
+}
+
+func keys(m interface{}) []string {
+	var ret []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		ret = append(ret, k.String())
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// checkSchema 함수는 v를 JSON으로 변환한 속성 이름이 s의 속성과 같은지 확인합니다.
+// 스키마에 속성이 직접 정의된 하위 객체도 확인합니다.
+func checkSchema(t *testing.T, name string, s schema, v interface{}) {
+	t.Helper()
+	buf, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	var got map[string]json.RawMessage
+	if err := json.Unmarshal(buf, &got); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if g, w := keys(got), keys(s.Properties); !reflect.DeepEqual(g, w) {
+		t.Errorf("%s: JSON properties = %v, OpenAPI schema has %v", name, g, w)
+	}
+	for prop, sub := range s.Properties {
+		if sub.Properties == nil || got[prop] == nil {
+			continue
+		}
+		var nested map[string]interface{}
+		if err := json.Unmarshal(got[prop], &nested); err != nil {
+			t.Fatalf("%s.%s: %v", name, prop, err)
+		}
+		checkSchema(t, name+"."+prop, sub, nested)
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	var spec struct {
+		Components struct {
+			Schemas map[string]schema
+		}
+	}
+	if err := json.Unmarshal([]byte(OpenAPI), &spec); err != nil {
+		t.Fatalf("OpenAPI is not valid JSON: %v", err)
+	}
+
+	// 스키마 이름별 형식. 같은 스키마를 쓰는 형식은 모두 확인합니다.
+	types := map[string][]interface{}{
+		"Record":              {Record{}},
+		"CharacterRequest":    {CharacterRequest{}},
+		"FormerName":          {FormerName{}},
+		"RankContext":         {RankContext{}},
+		"RankResponse":        {RankResponse{}},
+		"HistoryResponse":     {HistoryResponse{}},
+		"Leaderboard":         {Leaderboard{}},
+		"LeaderboardResponse": {LeaderboardResponse{}},
+		"SearchCandidate":     {SearchCandidate{}},
+		"SearchResponse":      {SearchResponse{}},
+		"JobStats":            {JobStats{}},
+		"JobStatsResponse":    {JobStatsResponse{}},
+		"CompareTarget":       {CompareTarget{}},
+		"CompareWeek":         {CompareWeek{}},
+		"CompareResponse":     {CompareResponse{}},
+		"NamedID":             {World{}, Category{}},
+		"WorldsResponse":      {WorldsResponse{}},
+		"ErrorResponse":       {ErrorResponse{}},
+	}
+	for name, s := range spec.Components.Schemas {
+		vs, ok := types[name]
+		if !ok {
+			t.Errorf("schema %s has no Go type in this test", name)
+			continue
+		}
+		for _, v := range vs {
+			checkSchema(t, name+"("+reflect.TypeOf(v).Name()+")", s, filled(reflect.TypeOf(v)).Interface())
+		}
+	}
+	for name := range types {
+		if _, ok := spec.Components.Schemas[name]; !ok {
+			t.Errorf("type for %s has no schema in OpenAPI", name)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// Package api 는 무릉도장 검색기 서버의 HTTP API가 주고받는 형식과 그 클라이언트입니다.
+// 서버(dojangserver)와 클라이언트(dojangclient) 모두 이 패키지의 형식을 사용합니다.
+package api
+
+// Record 는 API 응답에 쓰이는 랭킹 기록입니다. DB에 저장되는 형식(storage.Record)과는 따로 정의되며, 서버가 변환해서 보냅니다.
+type Record struct {
+	Rank       int64  `json:"rank,string"`
+	Move       int64  `json:"move,string"`
+	IconURL    string `json:"icon"`
+	Name       string `json:"nick"`
+	Job        string `json:"job"`
+	DetailJob  string `json:"detail_job"`
+	Level      int64  `json:"level"`
+	Exp        int64  `json:"exp"`
+	Popularity int64  `json:"popular"`
+	FloorStr   string `json:"floor"`
+	Duration   string `json:"duration"`
+	GuildID    int64  `json:"guild_worldid,string"` // ?
+
+	Second          int   `json:"second,omitempty"`
+	Minute          int   `json:"minute,omitempty"`
+	World           int   `json:"world,omitempty"`
+	Floor           int   `json:"rawfloor,omitempty"`
+	Type            int   `json:"type,omitempty"`
+	CheckedTimeUnix int64 `json:"checkedtime,omitempty"`
+}
+
+// FullSec 함수는 소요 시간을 초 단위로 반환합니다.
+func (r *Record) FullSec() int {
+	return r.Second + r.Minute*60
+}
+
+// CharacterRequest 는 /getrank, /history 에 POST하는 요청입니다.
+type CharacterRequest struct {
+	World int
+	Type  int
+	Name  string
+}
+
+// FormerName 은 예전 닉네임과 그 닉네임을 쓰던 서버입니다.
+type FormerName struct {
+	Name      string
+	World     int
+	WorldName string
+	Until     string // 마지막으로 랭킹에 오른 주 (예: 2018-W09)
+}
+
+// RankContext 는 한 기록이 같은 서버, 카테고리의 다른 기록들 사이에서 차지하는 위치입니다.
+type RankContext struct {
+	Week       string  // 비교한 주. 전체 기간 최고 기록과 비교했다면 비어 있습니다.
+	Rank       int     // 이 기록보다 좋은 기록 수 + 1
+	Total      int     // 비교한 기록 수
+	Percentile float64 // 이 기록보다 나쁜 기록의 비율(%)
+	TopPercent float64 // 상위 몇 %인지 (Rank / Total)
+	SameFloor  int     // 같은 층에 도달한 인원 (본인 포함)
+	NextFloor  int     // 누군가 도달한 더 높은 층 중 가장 낮은 층. 최고층이면 0
+	FloorGap   int     // NextFloor까지 남은 층수
+}
+
+// RankResponse 는 /getrank 와 /api/v1/characters 의 응답입니다.
+type RankResponse struct {
+	Ok          bool
+	World       int
+	WorldName   string
+	Type        int
+	ID          uint64 // 닉네임이 바뀌어도 유지되는 캐릭터 ID
+	Rank        Record
+	MRank       Record
+	FormerNames []FormerName
+	Weekly      *RankContext // 최근 기록이 속한 주의 기록들 사이에서의 위치
+	AllTime     *RankContext // 모든 캐릭터의 최고 기록 사이에서 최고 기록의 위치
+	Start       int64
+	End         int64
+}
+
+// HistoryResponse 는 /history 의 응답입니다. History는 오래된 주부터 정렬되어 있습니다.
+type HistoryResponse struct {
+	Ok      bool
+	History []Record
+	Start   int64
+	End     int64
+}
+
+// Leaderboard 는 한 서버의 순위표입니다.
+type Leaderboard struct {
+	World int
+	Name  string
+	Ranks []Record
+}
+
+// LeaderboardResponse 는 /leaderboard 의 응답입니다.
+type LeaderboardResponse struct {
+	Ok     bool
+	Type   int
+	Week   string
+	Worlds []Leaderboard
+}
+
+// SearchCandidate 는 닉네임 검색 결과 하나입니다.
+type SearchCandidate struct {
+	World     int
+	WorldName string
+	Name      string
+	Job       string
+	DetailJob string
+	Floor     int
+	Duration  string
+	Distance  int // 0이면 접두사 일치입니다.
+}
+
+// SearchResponse 는 /search 의 응답입니다.
+type SearchResponse struct {
+	Ok         bool
+	Candidates []SearchCandidate
+}
+
+// JobStats 는 한 세부 직업의 기록 통계입니다. 층수 백분위는 낮은 기록부터 센 값입니다.
+type JobStats struct {
+	Job         string
+	DetailJob   string
+	Count       int
+	MedianFloor int
+	P25Floor    int
+	P75Floor    int
+	P90Floor    int
+	MedianSec   int // 소요 시간의 중앙값(초)
+	Best        Record
+}
+
+// JobStatsResponse 는 /stats/jobs 의 응답입니다.
+type JobStatsResponse struct {
+	Ok      bool
+	World   int // 모든 서버를 합친 경우 0
+	Type    int
+	Week    string
+	Total   int
+	Classes []JobStats
+}
+
+// CompareTarget 은 비교할 캐릭터입니다.
+type CompareTarget struct {
+	World     int
+	WorldName string
+	Name      string
+	Found     bool // 주별 기록이 하나라도 있는지
+	Wins      int  // 가장 좋은 기록을 낸 주의 수
+}
+
+// CompareWeek 는 한 주의 비교 결과입니다. Records는 CompareResponse.Characters와 같은 순서이며,
+// 그 주에 기록이 없는 캐릭터는 nil입니다.
+type CompareWeek struct {
+	Week    string
+	Records []*Record
+	Best    []int // 그 주에 가장 좋은 기록을 낸 캐릭터의 위치. 같은 기록이면 여럿입니다.
+}
+
+// CompareResponse 는 /compare 의 응답입니다. Weeks는 오래된 주부터 정렬되어 있습니다.
+type CompareResponse struct {
+	Ok         bool
+	Type       int
+	Characters []CompareTarget
+	Weeks      []CompareWeek
+}
+
+// World 는 서버 번호와 이름입니다.
+type World struct {
+	ID   int
+	Name string
+}
+
+// Category 는 무릉도장 카테고리(cateType)와 그 이름입니다.
+type Category struct {
+	ID   int
+	Name string
+}
+
+// WorldsResponse 는 /api/v1/worlds 의 응답입니다. 첫 카테고리가 서버의 기본 카테고리입니다.
+type WorldsResponse struct {
+	Worlds     []World
+	Categories []Category
+}
+
+// Error 는 /api/v1 엔드포인트의 오류입니다. 응답 본문은 {"Error": Error} 형식입니다.
+type Error struct {
+	Status  int    // HTTP 상태 코드
+	Code    string // 기계가 구분하기 위한 짧은 코드 (예: unknown_character)
+	Message string
+}
+
+func (e *Error) Error() string {
+	return "dojang api: " + e.Code + ": " + e.Message
+}
+
+// ErrorResponse 는 오류 응답 본문입니다.
+type ErrorResponse struct {
+	Error Error
+}
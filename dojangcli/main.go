@@ -0,0 +1,203 @@
+// dojangcli 는 무릉도장 검색기 서버에 질의하는 명령줄 클라이언트입니다.
+// dojangclient(Windows GUI)와 같은 API를 사용하며 모든 플랫폼에서 빌드됩니다.
+//
+//	dojangcli rank -world 리부트 닉네임
+//	dojangcli history -world 리부트2 -json 닉네임
+//	dojangcli leaderboard -world 리부트 -week 2018-W09 -n 20
+//	dojangcli search 닉네임
+//
+// 서버 주소는 -server 플래그나 DOJANG_SERVER 환경변수로 지정합니다.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cr0sh/dojangsearch/api"
+)
+
+const defaultServerURL = "http://127.0.0.1:4412"
+
+const usage = `Usage: dojangcli <command> [flags] [args]
+
+Commands:
+  rank         Show recent and best records of a character
+  history      Show weekly records of a character
+  leaderboard  List top records per world
+  search       Search a nickname across all worlds
+
+Run 'dojangcli <command> -h' for the flags of each command.
+`
+
+// options 는 모든 명령이 공통으로 받는 플래그입니다.
+type options struct {
+	server  *string
+	typeid  *int
+	jsonOut *bool
+}
+
+func newFlagSet(name string) (*flag.FlagSet, *options) {
+	server := os.Getenv("DOJANG_SERVER")
+	if server == "" {
+		server = defaultServerURL
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	return fs, &options{
+		server:  fs.String("server", server, "Base URL of the dojangserver(defaults to $DOJANG_SERVER)"),
+		typeid:  fs.Int("type", 0, "Mu Lung Dojo category(cateType), 0 for the server default"),
+		jsonOut: fs.Bool("json", false, "Print the raw JSON response instead of a table"),
+	}
+}
+
+func (o *options) client() *api.Client {
+	return api.NewClient(*o.server)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "rank":
+		err = runRank(args)
+	case "history":
+		err = runHistory(args)
+	case "leaderboard":
+		err = runLeaderboard(args)
+	case "search":
+		err = runSearch(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "dojangcli: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dojangcli:", err)
+		os.Exit(1)
+	}
+}
+
+// characterArgs 함수는 rank, history 명령의 플래그와 닉네임을 읽습니다.
+func characterArgs(name string, args []string) (*options, string, string) {
+	fs, o := newFlagSet(name)
+	world := fs.String("world", "", "World name or number(required)")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: dojangcli %s -world <world> [flags] <nickname>\n", name)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *world == "" || fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	return o, *world, fs.Arg(0)
+}
+
+func runRank(args []string) error {
+	o, world, name := characterArgs("rank", args)
+	resp, err := o.client().Character(world, *o.typeid, name)
+	if err != nil {
+		return err
+	}
+	if *o.jsonOut {
+		return printJSON(resp)
+	}
+	printRank(resp)
+	return nil
+}
+
+func runHistory(args []string) error {
+	o, world, name := characterArgs("history", args)
+	c := o.client()
+	// /history 는 서버 번호만 받으므로 먼저 캐릭터를 조회해 서버와 카테고리를 정합니다.
+	rank, err := c.Character(world, *o.typeid, name)
+	if err != nil {
+		return err
+	}
+	resp, err := c.History(rank.World, rank.Type, rank.Rank.Name)
+	if err != nil {
+		return err
+	}
+	if *o.jsonOut {
+		return printJSON(resp)
+	}
+	printHistory(rank, resp)
+	return nil
+}
+
+func runLeaderboard(args []string) error {
+	fs, o := newFlagSet("leaderboard")
+	world := fs.String("world", "", "World name or number, empty for all worlds")
+	week := fs.String("week", "", "ISO week such as 2018-W09, empty for all-time best records")
+	job := fs.String("job", "", "Job or detailed job to filter by")
+	n := fs.Int("n", 10, "Number of records per world")
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	query := api.LeaderboardQuery{Type: *o.typeid, Week: *week, Job: *job, N: *n}
+	// 서버 이름은 응답의 Name으로 고릅니다. 번호라면 서버에서 거릅니다.
+	if id, err := strconv.Atoi(*world); err == nil {
+		query.World = id
+	}
+	resp, err := o.client().Leaderboard(query)
+	if err != nil {
+		return err
+	}
+	if query.World == 0 && *world != "" {
+		var worlds []api.Leaderboard
+		for _, l := range resp.Worlds {
+			if strings.EqualFold(l.Name, *world) {
+				worlds = append(worlds, l)
+			}
+		}
+		if len(worlds) == 0 {
+			return fmt.Errorf("unknown world %q", *world)
+		}
+		resp.Worlds = worlds
+	}
+	if *o.jsonOut {
+		return printJSON(resp)
+	}
+	printLeaderboard(resp)
+	return nil
+}
+
+func runSearch(args []string) error {
+	fs, o := newFlagSet("search")
+	n := fs.Int("n", 0, "Maximum number of candidates, 0 for the server default")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: dojangcli search [flags] <nickname>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	resp, err := o.client().Search(fs.Arg(0), *o.typeid, *n)
+	if err != nil {
+		return err
+	}
+	if *o.jsonOut {
+		return printJSON(resp)
+	}
+	printSearch(resp)
+	return nil
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/api"
+)
+
+const dateFormat = "2006-01-02"
+
+func newTable() *tabwriter.Writer {
+	return tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+}
+
+func formatRecord(r api.Record) string {
+	return fmt.Sprintf("%d층\t%d분 %d초\t%s", r.Floor, r.Minute, r.Second,
+		time.Unix(r.CheckedTimeUnix, 0).Format(dateFormat))
+}
+
+func formatContext(c *api.RankContext) string {
+	if c == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d / %d위 (상위 %.1f%%)", c.Rank, c.Total, c.TopPercent)
+}
+
+func printRank(resp *api.RankResponse) {
+	fmt.Printf("%s (%s, %s %s Lv.%d)\n", resp.Rank.Name, resp.WorldName,
+		resp.Rank.Job, resp.Rank.DetailJob, resp.Rank.Level)
+	for _, f := range resp.FormerNames {
+		fmt.Printf("  예전 닉네임: %s (%s, %s까지)\n", f.Name, f.WorldName, f.Until)
+	}
+
+	t := newTable()
+	fmt.Fprintln(t, "\t층\t시간\t달성 시각\t순위")
+	fmt.Fprintf(t, "최근 기록\t%s\t%s\n", formatRecord(resp.Rank), formatContext(resp.Weekly))
+	fmt.Fprintf(t, "최고 기록\t%s\t%s\n", formatRecord(resp.MRank), formatContext(resp.AllTime))
+	t.Flush()
+
+	if resp.Start > 0 && resp.End > 0 {
+		fmt.Printf("데이터 수집 기간: %s ~ %s\n",
+			time.Unix(resp.Start, 0).Format(dateFormat), time.Unix(resp.End, 0).Format(dateFormat))
+	}
+}
+
+func printHistory(rank *api.RankResponse, resp *api.HistoryResponse) {
+	fmt.Printf("%s (%s)\n", rank.Rank.Name, rank.WorldName)
+	t := newTable()
+	fmt.Fprintln(t, "닉네임\t층\t시간\t달성 시각")
+	for i := len(resp.History) - 1; i >= 0; i-- {
+		r := resp.History[i]
+		fmt.Fprintf(t, "%s\t%s\n", r.Name, formatRecord(r))
+	}
+	t.Flush()
+}
+
+func printLeaderboard(resp *api.LeaderboardResponse) {
+	week := resp.Week
+	if week == "" {
+		week = "전체 기간"
+	}
+	for i, l := range resp.Worlds {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s)\n", l.Name, week)
+		t := newTable()
+		fmt.Fprintln(t, "순위\t닉네임\t직업\t층\t시간\t달성 시각")
+		for j, r := range l.Ranks {
+			fmt.Fprintf(t, "%d\t%s\t%s\t%s\n", j+1, r.Name, r.DetailJob, formatRecord(r))
+		}
+		t.Flush()
+	}
+}
+
+func printSearch(resp *api.SearchResponse) {
+	if len(resp.Candidates) == 0 {
+		fmt.Println("검색 결과가 없습니다.")
+		return
+	}
+	t := newTable()
+	fmt.Fprintln(t, "서버\t닉네임\t직업\t층\t시간")
+	for _, c := range resp.Candidates {
+		fmt.Fprintf(t, "%s\t%s\t%s\t%d층\t%s\n", c.WorldName, c.Name, c.DetailJob, c.Floor, c.Duration)
+	}
+	t.Flush()
+}
@@ -1,98 +1,116 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"net/http"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/cr0sh/dojangsearch/api"
 	"github.com/lxn/walk"
 	. "github.com/lxn/walk/declarative"
 )
 
-type rankItem struct {
-	Rank       int64  `json:"rank,string"`
-	Move       int64  `json:"move,string"`
-	IconURL    string `json:"icon"`
-	Name       string `json:"nick"`
-	Job        string `json:"job"`
-	DetailJob  string `json:"detail_job"`
-	Level      int64  `json:"level"`
-	Exp        int64  `json:"exp"`
-	Popularity int64  `json:"popular"`
-	FloorStr   string `json:"floor"`
-	Duration   string `json:"duration"`
-	GuildID    int64  `json:"guild_worldid,string"` // ?
-
-	Second          int   `json:"second,omitempty"`
-	Minute          int   `json:"minute,omitempty"`
-	World           int   `json:"world,omitempty"`
-	Floor           int   `json:"rawfloor,omitempty"`
-	Type            int   `json:"type,omitempty"`
-	CheckedTimeUnix int64 `json:"checkedtime,omitempty"`
-}
-
 const timeFormat = "2006년 1월 2일"
 
-var nameLE *walk.LineEdit
+var nameLE, serverLE *walk.LineEdit
 var searchPB *walk.PushButton
 var maxFloorNE, recentFloorNE,
 	maxMinuteNE, recentMinuteNE,
 	maxSecondNE, recentSecondNE *walk.NumberEdit
 var maxDateLE, recentDateLE *walk.LineEdit
 var termLB *walk.Label
-var serverCB *walk.ComboBox
+var historyTE *walk.TextEdit
+var serverCB, categoryCB *walk.ComboBox
 var mw *walk.MainWindow
-var serverList = []*struct{ Name string }{
-	{"리부트"},
-	{"리부트2"},
+
+// listItem 은 콤보박스 항목입니다.
+type listItem struct {
+	ID   int
+	Name string
+}
+
+// 서버에서 목록을 받아오지 못했을 때 사용하는 기본 목록입니다.
+var serverList = []*listItem{
+	{1, "리부트"},
+	{12, "리부트2"},
 }
 
-var serverIDList = []int{
-	1,
-	12,
+var categoryList = []*listItem{
+	{2, "챌린저"},
+	{1, "일반"},
+	{3, "기타"},
 }
 
-const serverURL = `http://127.0.0.1:4412/getrank`
+var cfg = loadSettings()
+var client = api.NewClient(cfg.Server)
+
+// indexOf 함수는 list에서 id의 위치를 반환합니다. 없으면 0입니다.
+func indexOf(list []*listItem, id int) int {
+	for i, item := range list {
+		if item.ID == id {
+			return i
+		}
+	}
+	return 0
+}
+
+// connect 함수는 입력한 서버 주소로 서버와 카테고리 목록을 받아오고 설정에 저장합니다.
+// 목록을 받아오지 못하면 이전 목록을 그대로 사용합니다.
+func connect() {
+	url := strings.TrimSpace(serverLE.Text())
+	if url == "" {
+		url = defaultServerURL
+		serverLE.SetText(url)
+	}
+	client = api.NewClient(url)
+
+	resp, err := client.Worlds()
+	if err != nil {
+		walk.MsgBox(mw, "오류", "서버 목록을 불러오지 못했습니다: "+err.Error(), walk.MsgBoxOK|walk.MsgBoxIconError)
+	} else if len(resp.Worlds) > 0 && len(resp.Categories) > 0 {
+		serverList, categoryList = nil, nil
+		for _, w := range resp.Worlds {
+			serverList = append(serverList, &listItem{w.ID, w.Name})
+		}
+		for _, c := range resp.Categories {
+			categoryList = append(categoryList, &listItem{c.ID, c.Name})
+		}
+	}
+	serverCB.SetModel(serverList)
+	categoryCB.SetModel(categoryList)
+	serverCB.SetCurrentIndex(indexOf(serverList, cfg.World))
+	categoryCB.SetCurrentIndex(indexOf(categoryList, cfg.Type))
+
+	cfg.Server = url
+	saveSettings()
+}
+
+func saveSettings() {
+	if err := cfg.save(); err != nil {
+		walk.MsgBox(mw, "오류", "설정을 저장하지 못했습니다: "+err.Error(), walk.MsgBoxOK|walk.MsgBoxIconWarning)
+	}
+}
 
 func search() {
 	searchPB.SetEnabled(false)
 	defer searchPB.SetEnabled(true)
 
-	var request struct {
-		World int
-		Type  int
-		Name  string
+	if serverCB.CurrentIndex() < 0 || categoryCB.CurrentIndex() < 0 {
+		return
 	}
 
-	request.World = serverIDList[serverCB.CurrentIndex()]
-	request.Type = 2
+	var request api.CharacterRequest
+	request.World = serverList[serverCB.CurrentIndex()].ID
+	request.Type = categoryList[categoryCB.CurrentIndex()].ID
 	request.Name = strings.Trim(nameLE.Text(), " \r\n")
 
-	var b bytes.Buffer
-	json.NewEncoder(&b).Encode(request)
-	resp, err := http.Post(serverURL, "application/json", &b)
+	response, err := client.Rank(request.World, request.Type, request.Name)
 	if err != nil {
 		walk.MsgBox(mw, "오류", err.Error(), walk.MsgBoxOK|walk.MsgBoxIconError)
 		return
 	}
 
-	var response struct {
-		Ok    bool
-		Rank  rankItem
-		MRank rankItem
-		Start int64
-		End   int64
-	}
-	dec := json.NewDecoder(resp.Body)
-	if err := dec.Decode(&response); err != nil {
-		walk.MsgBox(mw, "오류", err.Error(), walk.MsgBoxOK|walk.MsgBoxIconError)
-		return
-	}
-
 	if !response.Ok {
 		for _, ne := range []*walk.NumberEdit{
 			maxFloorNE, recentFloorNE,
@@ -103,8 +121,11 @@ func search() {
 		}
 		recentDateLE.SetText("검색 실패")
 		maxDateLE.SetText("검색 실패")
+		historyTE.SetText("")
 	} else {
 		nameLE.SetText(response.Rank.Name)
+		cfg.World, cfg.Type, cfg.Name = request.World, request.Type, response.Rank.Name
+		saveSettings()
 
 		recentFloorNE.SetValue(float64(response.Rank.Floor))
 		recentMinuteNE.SetValue(float64(response.Rank.Minute))
@@ -115,6 +136,13 @@ func search() {
 		maxMinuteNE.SetValue(float64(response.MRank.Minute))
 		maxSecondNE.SetValue(float64(response.MRank.Second))
 		maxDateLE.SetText(time.Unix(response.MRank.CheckedTimeUnix, 0).Format(timeFormat))
+
+		history, err := client.History(request.World, request.Type, request.Name)
+		if err != nil {
+			walk.MsgBox(mw, "오류", err.Error(), walk.MsgBoxOK|walk.MsgBoxIconError)
+		} else {
+			historyTE.SetText(formatHistory(history.History))
+		}
 	}
 
 	if response.Start > 0 && response.End > 0 {
@@ -123,6 +151,16 @@ func search() {
 	}
 }
 
+func formatHistory(history []api.Record) string {
+	ret := ""
+	for i := len(history) - 1; i >= 0; i-- {
+		ret += fmt.Sprintf("%s 주: %d층 %d분 %d초\r\n",
+			time.Unix(history[i].CheckedTimeUnix, 0).Format(timeFormat),
+			history[i].Floor, history[i].Minute, history[i].Second)
+	}
+	return ret
+}
+
 func main() {
 	if runtime.GOMAXPROCS(0) < 2 {
 		runtime.GOMAXPROCS(2)
@@ -133,10 +171,14 @@ func main() {
 		Title:    "무릉전적 v2",
 		Layout:   Grid{Columns: 3},
 		Children: []Widget{
+			Label{Text: "서버 주소:"},
+			LineEdit{AssignTo: &serverLE, Text: cfg.Server},
+			PushButton{Text: "연결", OnClicked: connect},
 			Label{Text: "닉네임:"},
-			LineEdit{AssignTo: &nameLE, MinSize: Size{100, 0}, OnEditingFinished: search},
-			ComboBox{AssignTo: &serverCB, DisplayMember: "Name", Model: serverList, CurrentIndex: 0},
-			PushButton{AssignTo: &searchPB, ColumnSpan: 3, Text: "검색", OnClicked: search},
+			LineEdit{AssignTo: &nameLE, MinSize: Size{100, 0}, Text: cfg.Name, OnEditingFinished: search},
+			ComboBox{AssignTo: &serverCB, DisplayMember: "Name", Model: serverList, CurrentIndex: indexOf(serverList, cfg.World)},
+			ComboBox{AssignTo: &categoryCB, DisplayMember: "Name", Model: categoryList, CurrentIndex: indexOf(categoryList, cfg.Type)},
+			PushButton{AssignTo: &searchPB, ColumnSpan: 2, Text: "검색", OnClicked: search},
 			Composite{
 				ColumnSpan: 3,
 				Layout:     Grid{Columns: 2, MarginsZero: true},
@@ -163,13 +205,18 @@ func main() {
 					},
 				},
 			},
+			Label{ColumnSpan: 3, Text: "주간 기록"},
+			TextEdit{AssignTo: &historyTE, ColumnSpan: 3, MinSize: Size{0, 100}, ReadOnly: true, VScroll: true},
 			Label{AssignTo: &termLB, ColumnSpan: 3, Text: "데이터 수집 기간: 2000-00-00 ~ 2000-00-00"},
 			Label{ColumnSpan: 3, Text: "정확한 검색을 보증하지 않습니다 (Beta)", TextColor: walk.RGB(255, 0, 0)},
 			Label{ColumnSpan: 3, Text: "달성 시각은 최대 ±1일의 오차가 있습니다.", TextColor: walk.RGB(0, 0, 255)},
 		},
 	}
 
-	if _, err := mwd.Run(); err != nil {
+	if err := mwd.Create(); err != nil {
 		fmt.Println("GUI Error:", err)
+		return
 	}
+	connect()
+	mw.Run()
 }
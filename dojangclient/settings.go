@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const defaultServerURL = `http://127.0.0.1:4412`
+
+// settings 는 다음 실행 때 복원할 사용자 설정입니다.
+type settings struct {
+	Server string // 검색기 서버 주소
+	World  int    // 마지막으로 검색한 서버
+	Type   int    // 마지막으로 검색한 카테고리
+	Name   string // 마지막으로 검색한 닉네임
+}
+
+// settingsPath 함수는 설정 파일 경로를 반환합니다. %APPDATA% 가 없으면 현재 디렉토리를 사용합니다.
+func settingsPath() string {
+	if dir := os.Getenv("APPDATA"); dir != "" {
+		return filepath.Join(dir, "dojangsearch", "dojangclient.json")
+	}
+	return "dojangclient.json"
+}
+
+// loadSettings 함수는 설정 파일을 읽습니다. 파일이 없거나 읽을 수 없으면 기본값을 반환합니다.
+func loadSettings() *settings {
+	s := &settings{Server: defaultServerURL}
+	buf, err := ioutil.ReadFile(settingsPath())
+	if err != nil {
+		return s
+	}
+	if err := json.Unmarshal(buf, s); err != nil || s.Server == "" {
+		return &settings{Server: defaultServerURL}
+	}
+	return s
+}
+
+// save 함수는 설정을 파일에 씁니다.
+func (s *settings) save() error {
+	path := settingsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf, 0644)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+const apiPrefix = "/api/v1/"
+
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	response := api.ErrorResponse{Error: api.Error{Status: status, Code: code, Message: message}}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
+
+// apiRecord 함수는 DB에 저장된 기록을 API 응답 형식으로 변환합니다.
+func apiRecord(r storage.Record) api.Record {
+	return api.Record{
+		Rank:            r.Rank,
+		Move:            r.Move,
+		IconURL:         r.IconURL,
+		Name:            r.Name,
+		Job:             r.Job,
+		DetailJob:       r.DetailJob,
+		Level:           r.Level,
+		Exp:             r.Exp,
+		Popularity:      r.Popularity,
+		FloorStr:        r.FloorStr,
+		Duration:        r.Duration,
+		GuildID:         r.GuildID,
+		Second:          r.Second,
+		Minute:          r.Minute,
+		World:           r.World,
+		Floor:           r.Floor,
+		Type:            r.Type,
+		CheckedTimeUnix: r.CheckedTimeUnix,
+	}
+}
+
+// apiRecords 함수는 기록 목록을 API 응답 형식으로 변환합니다.
+func apiRecords(rs []storage.Record) []api.Record {
+	if rs == nil {
+		return nil
+	}
+	ret := make([]api.Record, len(rs))
+	for i, r := range rs {
+		ret[i] = apiRecord(r)
+	}
+	return ret
+}
+
+func writeAPI(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
+
+// apiType 함수는 type 쿼리 파라미터를 읽습니다. 없으면 기본 카테고리를 사용합니다.
+func apiType(w http.ResponseWriter, q url.Values) (int, bool) {
+	s := q.Get("type")
+	if s == "" {
+		return categories()[0], true
+	}
+	typeid, err := strconv.Atoi(s)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_type", "type must be a number")
+		return 0, false
+	}
+	if !knownCategory(typeid) {
+		writeAPIError(w, http.StatusNotFound, "unknown_type", "unknown category "+s)
+		return 0, false
+	}
+	return typeid, true
+}
+
+// handleAPICharacter 함수는 GET /api/v1/characters/{world}/{name}?type= 요청을 처리합니다.
+// world는 서버 번호 또는 이름입니다. 응답 형식은 /getrank 와 같으며, 기록이 없으면 404를 반환합니다.
+func handleAPICharacter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use GET")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, apiPrefix+"characters/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		writeAPIError(w, http.StatusBadRequest, "malformed_path", "expected /api/v1/characters/{world}/{name}")
+		return
+	}
+	world, ok := parseWorld(parts[0])
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "unknown_world", "unknown world "+parts[0])
+		return
+	}
+	typeid, ok := apiType(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	response, err := readRank(world, typeid, parts[1])
+	if err != nil {
+		errLog.Println("HTTP: readRank failed:", err)
+		writeAPIError(w, http.StatusInternalServerError, "internal", "database error")
+		return
+	}
+	if !response.Ok {
+		writeAPIError(w, http.StatusNotFound, "unknown_character", "no records for "+parts[1])
+		return
+	}
+	writeAPI(w, response)
+}
+
+// handleAPIWorlds 함수는 GET /api/v1/worlds 요청을 처리합니다. 수집 중인 서버와 카테고리를 설정 순서대로 반환합니다.
+func handleAPIWorlds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "use GET")
+		return
+	}
+
+	var response api.WorldsResponse
+	for _, world := range servers() {
+		response.Worlds = append(response.Worlds, api.World{ID: world, Name: serverNameOf(world)})
+	}
+	for _, typeid := range categories() {
+		response.Categories = append(response.Categories, api.Category{ID: typeid, Name: categoryNameOf(typeid)})
+	}
+	writeAPI(w, response)
+}
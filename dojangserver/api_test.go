@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+func TestHandleAPICharacter(t *testing.T) {
+	crawlTestData(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   string // 비어 있으면 성공 응답이어야 합니다.
+	}{
+		{name: "ok", path: "/api/v1/characters/1/" + url.PathEscape("무릉고수"), status: http.StatusOK},
+		{name: "world name", path: "/api/v1/characters/" + url.PathEscape(serverName[1]) + "/bishop?type=2", status: http.StatusOK},
+		{name: "method", method: http.MethodPost, path: "/api/v1/characters/1/bishop", status: http.StatusMethodNotAllowed, code: "method_not_allowed"},
+		{name: "missing name", path: "/api/v1/characters/1/", status: http.StatusBadRequest, code: "malformed_path"},
+		{name: "missing world", path: "/api/v1/characters//bishop", status: http.StatusBadRequest, code: "malformed_path"},
+		{name: "extra segment", path: "/api/v1/characters/1/bishop/x", status: http.StatusBadRequest, code: "malformed_path"},
+		{name: "invalid type", path: "/api/v1/characters/1/bishop?type=x", status: http.StatusBadRequest, code: "invalid_type"},
+		{name: "unknown type", path: "/api/v1/characters/1/bishop?type=99", status: http.StatusNotFound, code: "unknown_type"},
+		{name: "unknown world", path: "/api/v1/characters/9999/bishop", status: http.StatusNotFound, code: "unknown_world"},
+		{name: "unknown character", path: "/api/v1/characters/1/" + url.PathEscape("없는캐릭터"), status: http.StatusNotFound, code: "unknown_character"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			handleAPICharacter(w, httptest.NewRequest(method, tt.path, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tt.status, w.Body.String())
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if tt.code == "" {
+				var resp api.RankResponse
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || !resp.Ok {
+					t.Errorf("response = %+v, %v, want Ok", resp, err)
+				}
+				return
+			}
+			checkAPIError(t, w.Body.Bytes(), tt.status, tt.code)
+		})
+	}
+}
+
+func TestHandleAPICharacterDBError(t *testing.T) {
+	crawlTestData(t)
+	store.Close()
+
+	w := httptest.NewRecorder()
+	handleAPICharacter(w, httptest.NewRequest(http.MethodGet, "/api/v1/characters/1/bishop", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500 (body %q)", w.Code, w.Body.String())
+	}
+	checkAPIError(t, w.Body.Bytes(), http.StatusInternalServerError, "internal")
+}
+
+// checkAPIError 함수는 본문이 api.Client가 해석하는 {"Error":{...}} 형식인지 확인합니다.
+func checkAPIError(t *testing.T, body []byte, status int, code string) {
+	t.Helper()
+	var raw map[string]map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		t.Fatalf("error body %q: %v", body, err)
+	}
+	e, ok := raw["Error"]
+	if len(raw) != 1 || !ok {
+		t.Fatalf("error body %q, want a single Error object", body)
+	}
+	for _, field := range []string{"Status", "Code", "Message"} {
+		if _, ok := e[field]; !ok {
+			t.Errorf("error body %q has no Error.%s", body, field)
+		}
+	}
+	var resp api.ErrorResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error.Status != status || resp.Error.Code != code || resp.Error.Message == "" {
+		t.Errorf("error = %+v, want status %d, code %s", resp.Error, status, code)
+	}
+}
+
+func TestAPIClientCharacterError(t *testing.T) {
+	crawlTestData(t)
+	srv := httptest.NewServer(http.HandlerFunc(handleAPICharacter))
+	defer srv.Close()
+
+	c := api.NewClient(srv.URL)
+	if resp, err := c.Character("1", 2, "무릉고수"); err != nil || !resp.Ok {
+		t.Fatalf("Character = %+v, %v", resp, err)
+	}
+	_, err := c.Character("1", 2, "없는캐릭터")
+	e, ok := err.(*api.Error)
+	if !ok {
+		t.Fatalf("error = %#v, want *api.Error", err)
+	}
+	if e.Status != http.StatusNotFound || e.Code != "unknown_character" {
+		t.Errorf("error = %+v, want 404 unknown_character", e)
+	}
+}
+
+func TestAPIRecord(t *testing.T) {
+	// 모든 필드를 채운 기록을 변환했을 때 JSON이 같아야 합니다. 한쪽에만 필드를 추가하면 실패합니다.
+	var r storage.Record
+	v := reflect.ValueOf(&r).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		switch f := v.Field(i); f.Kind() {
+		case reflect.String:
+			f.SetString(v.Type().Field(i).Name)
+		case reflect.Int, reflect.Int64:
+			f.SetInt(int64(i + 1))
+		default:
+			t.Fatalf("unexpected field kind %s", f.Kind())
+		}
+	}
+	want, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(apiRecord(r))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("apiRecord JSON = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/subtle"
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+var adminToken *string
+var backupDir *string
+var backupKeep *int
+
+// checkAdmin 함수는 요청이 관리자 토큰을 가지고 있는지 확인합니다. 토큰이 설정되지 않았다면 항상 거부합니다.
+// 토큰은 Authorization: Bearer 헤더로 전달합니다.
+func checkAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if *adminToken == "" {
+		http.Error(w, "admin endpoints are disabled", http.StatusForbidden)
+		return false
+	}
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	given := strings.TrimPrefix(auth, "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(given), []byte(*adminToken)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// handleBackup 함수는 DB의 일관된 사본을 내려보냅니다. 백업 중에도 크롤링과 검색은 계속됩니다.
+func handleBackup(w http.ResponseWriter, r *http.Request) {
+	if !checkAdmin(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"database-%s.db\"", time.Now().Format("20060102-150405")))
+
+	verbLog.Println("HTTP: Streaming database backup to", r.RemoteAddr)
+	n, err := store.WriteTo(w)
+	if err != nil {
+		errLog.Println("HTTP: store.WriteTo failed:", err)
+		return
+	}
+	verbLog.Printf("HTTP: Database backup finished (%d bytes)", n)
+}
+
+func backupJob() {
+	path, err := store.Snapshot(*backupDir, *backupKeep)
+	if err != nil {
+		errLog.Println("Backup: store.Snapshot failed:", err)
+		notifier.Notify("DB 백업 오류: " + err.Error())
+		return
+	}
+	verbLog.Println("Backup: Wrote snapshot", path)
+}
+
+// runRestore 함수는 restore 하위 명령을 실행합니다. 서버가 실행 중이지 않을 때 사용해야 합니다.
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	path := fs.String("db", "database.db", "Path to the boltDB database file to replace")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: dojangserver restore [-db database.db] <snapshot>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return
+	}
+
+	verbLog.Println("Verifying and restoring", fs.Arg(0), "to", *path)
+	backup, err := storage.Restore(fs.Arg(0), *path)
+	if err != nil {
+		errLog.Fatal("storage.Restore:", err)
+	}
+	if backup != "" {
+		verbLog.Println("Previous database was kept as", backup)
+	}
+	verbLog.Println("Restore finished")
+}
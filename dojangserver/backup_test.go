@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckAdmin(t *testing.T) {
+	old := adminToken
+	defer func() { adminToken = old }()
+
+	tests := []struct {
+		token  string
+		header string
+		ok     bool
+		status int
+	}{
+		{token: "", header: "Bearer ", status: http.StatusForbidden},
+		{token: "secret", header: "Bearer secret", ok: true},
+		{token: "secret", header: "secret", status: http.StatusUnauthorized},
+		{token: "secret", header: "Basic secret", status: http.StatusUnauthorized},
+		{token: "secret", header: "Bearer wrong", status: http.StatusUnauthorized},
+		{token: "secret", header: "", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		token := tt.token
+		adminToken = &token
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/admin/backup", nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		if ok := checkAdmin(w, r); ok != tt.ok {
+			t.Errorf("token %q, header %q: ok = %v", tt.token, tt.header, ok)
+		}
+		if !tt.ok && w.Code != tt.status {
+			t.Errorf("token %q, header %q: status = %d, want %d", tt.token, tt.header, w.Code, tt.status)
+		}
+	}
+}
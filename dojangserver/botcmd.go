@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/storage"
+	"github.com/tucnak/telebot"
+)
+
+const botTopSize = 10
+
+// setupBotCommands 함수는 텔레그램 봇 명령을 등록합니다. /crawl, /crawllastweek 명령은
+// -clientid로 지정한 사용자(또는 채팅)만 사용할 수 있습니다.
+func setupBotCommands(b *telebot.Bot, adminID string) {
+	reply := func(m *telebot.Message, msg string) {
+		if _, err := b.Send(m.Chat, msg); err != nil {
+			warnLog.Println("Bot: Send failed:", err)
+		}
+	}
+	isAdmin := func(m *telebot.Message) bool {
+		return (m.Sender != nil && strconv.Itoa(m.Sender.ID) == adminID) ||
+			(m.Chat != nil && strconv.FormatInt(m.Chat.ID, 10) == adminID)
+	}
+
+	b.Handle("/rank", func(m *telebot.Message) {
+		args := strings.Fields(m.Payload)
+		if len(args) != 2 {
+			reply(m, "사용법: /rank <서버> <닉네임>")
+			return
+		}
+		world, ok := parseWorld(args[0])
+		if !ok {
+			reply(m, "알 수 없는 서버입니다: "+args[0])
+			return
+		}
+		reply(m, botRank(world, args[1]))
+	})
+
+	b.Handle("/top", func(m *telebot.Message) {
+		args := strings.Fields(m.Payload)
+		if len(args) != 1 {
+			reply(m, "사용법: /top <서버>")
+			return
+		}
+		world, ok := parseWorld(args[0])
+		if !ok {
+			reply(m, "알 수 없는 서버입니다: "+args[0])
+			return
+		}
+		reply(m, botTop(world))
+	})
+
+	b.Handle("/status", func(m *telebot.Message) {
+		reply(m, botStatus())
+	})
+
+	b.Handle("/crawl", func(m *telebot.Message) {
+		if !isAdmin(m) {
+			reply(m, "권한이 없습니다.")
+			return
+		}
+		reply(m, "크롤링 작업을 시작합니다.")
+		go crawlJob()
+	})
+
+	b.Handle("/crawllastweek", func(m *telebot.Message) {
+		if !isAdmin(m) {
+			reply(m, "권한이 없습니다.")
+			return
+		}
+		reply(m, "지난주 크롤링 작업을 시작합니다.")
+		go crawlJobLastWeek()
+	})
+}
+
+func formatRecord(r storage.Record) string {
+	return fmt.Sprintf("%d층 %d분 %d초 (%s)", r.Floor, r.Minute, r.Second, time.Unix(r.CheckedTimeUnix, 0).Format("2006-01-02"))
+}
+
+func botRank(world int, name string) string {
+	var lines []string
+	for _, typeid := range categories() {
+		c, err := store.GetCharacter(world, typeid, name)
+		if err != nil {
+			errLog.Println("Bot: store.GetCharacter failed:", err)
+			return "조회 중 오류가 발생했습니다."
+		}
+		if c == nil {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("[%s] %s %s/%s\n최고: %s\n최근: %s",
+			categoryNameOf(typeid), c.Max.Name, c.Max.Job, c.Max.DetailJob,
+			formatRecord(c.Max), formatRecord(c.Recent)))
+	}
+	if len(lines) == 0 {
+		return fmt.Sprintf("%s 서버에 저장된 %s의 전적이 없습니다.", serverNameOf(world), name)
+	}
+	return strings.Join(lines, "\n\n")
+}
+
+func botTop(world int) string {
+	typeid := categories()[0]
+	ranks, err := readLeaderboard(world, typeid, "", "", botTopSize)
+	if err != nil {
+		errLog.Println("Bot: readLeaderboard failed:", err)
+		return "조회 중 오류가 발생했습니다."
+	}
+	if len(ranks) == 0 {
+		return fmt.Sprintf("%s 서버에 저장된 전적이 없습니다.", serverNameOf(world))
+	}
+	ret := fmt.Sprintf("%s(%s) 최고 기록 상위 %d명", serverNameOf(world), categoryNameOf(typeid), len(ranks))
+	for i, r := range ranks {
+		ret += fmt.Sprintf("\n%d. %s (%s) %d층 %d분 %d초", i+1, r.Name, r.DetailJob, r.Floor, r.Minute, r.Second)
+	}
+	return ret
+}
+
+func botStatus() string {
+	running := func(lock sync.Locker, t *int64) string {
+		lock.Lock()
+		defer lock.Unlock()
+		if *t == 0 {
+			return "대기 중"
+		}
+		return time.Unix(*t, 0).Format(timeFormat) + "부터 실행 중"
+	}
+
+	ret := "크롤러: " + running(&lastCrawlTimeLock, &lastCrawlTime) +
+		"\n지난주 크롤러: " + running(&lastCrawlTimeLockLastWeek, &lastCrawlTimeLastWeek)
+	for _, typeid := range categories() {
+		ret += fmt.Sprintf("\n\n[%s]", categoryNameOf(typeid))
+		for _, world := range servers() {
+			meta, err := store.Meta(world, typeid)
+			if err != nil {
+				errLog.Println("Bot: store.Meta failed:", err)
+				return "조회 중 오류가 발생했습니다."
+			}
+			sizes, err := store.Sizes(world, typeid)
+			if err != nil {
+				errLog.Println("Bot: store.Sizes failed:", err)
+				return "조회 중 오류가 발생했습니다."
+			}
+			last := "없음"
+			if meta.End != 0 {
+				last = time.Unix(meta.End, 0).Format(timeFormat)
+			}
+			ret += fmt.Sprintf("\n%s: 마지막 갱신 %s, 최고 기록 %d명, 최근 기록 %d명, 주별 기록 %d명",
+				serverNameOf(world), last, sizes.Max, sizes.Recent, sizes.History)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+// 추이 그래프의 크기와 여백입니다.
+const (
+	chartWidth  = 640
+	chartHeight = 260
+	chartLeft   = 48
+	chartRight  = 56
+	chartTop    = 24
+	chartBottom = 40
+)
+
+// chartStep 함수는 span을 최대 n칸으로 나누는 1, 2, 5 배수의 눈금 간격을 반환합니다.
+func chartStep(span, n int) int {
+	for _, base := range []int{1, 10, 100} {
+		for _, m := range []int{1, 2, 5} {
+			if step := base * m; span/step <= n {
+				return step
+			}
+		}
+	}
+	return 1000
+}
+
+// renderTrend 함수는 주별 기록의 층수와 소요 시간 추이를 SVG로 그립니다.
+// 층수는 왼쪽 축(실선), 소요 시간은 오른쪽 축(점선)을 사용합니다. history는 오래된 주부터 정렬되어 있어야 합니다.
+func renderTrend(history []storage.Record) []byte {
+	minFloor, maxFloor, maxSec := history[0].Floor, history[0].Floor, 0
+	for _, r := range history {
+		if r.Floor < minFloor {
+			minFloor = r.Floor
+		}
+		if r.Floor > maxFloor {
+			maxFloor = r.Floor
+		}
+		if r.FullSec() > maxSec {
+			maxSec = r.FullSec()
+		}
+	}
+	floorStep := chartStep(maxFloor-minFloor+2, 5)
+	floorLow := (minFloor - 1) / floorStep * floorStep
+	floorHigh := (maxFloor + floorStep) / floorStep * floorStep
+	minStep := chartStep(maxSec/60+1, 5)
+	secHigh := (maxSec/60/minStep + 1) * minStep * 60
+
+	plotW := float64(chartWidth - chartLeft - chartRight)
+	plotH := float64(chartHeight - chartTop - chartBottom)
+	x := func(i int) float64 {
+		if len(history) == 1 {
+			return chartLeft + plotW/2
+		}
+		return chartLeft + plotW*float64(i)/float64(len(history)-1)
+	}
+	yFloor := func(f int) float64 {
+		return chartTop + plotH*float64(floorHigh-f)/float64(floorHigh-floorLow)
+	}
+	ySec := func(s int) float64 {
+		return chartTop + plotH*float64(secHigh-s)/float64(secHigh)
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`,
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/>`)
+
+	for f := floorLow; f <= floorHigh; f += floorStep {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#eee"/>`, chartLeft, yFloor(f), chartWidth-chartRight, yFloor(f))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" fill="#3273dc">%d층</text>`, chartLeft-6, yFloor(f)+4, f)
+	}
+	for s := 0; s <= secHigh; s += minStep * 60 {
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" fill="#ff7f0e">%d분</text>`, chartWidth-chartRight+6, ySec(s)+4, s/60)
+	}
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.0f" height="%.0f" fill="none" stroke="#ccc"/>`, chartLeft, chartTop, plotW, plotH)
+
+	// 주가 많으면 눈금 이름이 겹치지 않도록 일부만 표시합니다.
+	every := (len(history) + 7) / 8
+	for i, r := range history {
+		if i%every != 0 && i != len(history)-1 {
+			continue
+		}
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle" fill="#666">%s</text>`,
+			x(i), chartHeight-chartBottom+16, storage.WeekKey(time.Unix(r.CheckedTimeUnix, 0)))
+	}
+
+	var floorLine, secLine bytes.Buffer
+	for i, r := range history {
+		fmt.Fprintf(&floorLine, "%.1f,%.1f ", x(i), yFloor(r.Floor))
+		fmt.Fprintf(&secLine, "%.1f,%.1f ", x(i), ySec(r.FullSec()))
+	}
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="#ff7f0e" stroke-width="1.5" stroke-dasharray="4 3"/>`, bytes.TrimSpace(secLine.Bytes()))
+	fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="#3273dc" stroke-width="2"/>`, bytes.TrimSpace(floorLine.Bytes()))
+	for i, r := range history {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="#3273dc"><title>%s %s: %d층 %d분 %d초</title></circle>`,
+			x(i), yFloor(r.Floor), storage.WeekKey(time.Unix(r.CheckedTimeUnix, 0)), html.EscapeString(r.Name), r.Floor, r.Minute, r.Second)
+	}
+
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#3273dc">━ 층수</text>`, chartLeft, chartTop-8)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#ff7f0e">┅ 소요 시간</text>`, chartLeft+60, chartTop-8)
+	b.WriteString(`</svg>`)
+	return b.Bytes()
+}
+
+// handleHistoryChart 함수는 GET /history.svg?world=&type=&name= 요청에 캐릭터의 주별 기록 추이 그래프를 반환합니다.
+// world는 서버 번호 또는 이름이며, type이 없으면 기본 카테고리를 사용합니다.
+func handleHistoryChart(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+
+	q := r.URL.Query()
+	world, ok := parseWorld(q.Get("world"))
+	if !ok {
+		http.Error(w, "unknown world", http.StatusNotFound)
+		return
+	}
+	typeid := categories()[0]
+	if s := q.Get("type"); s != "" {
+		var err error
+		if typeid, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "invalid type", http.StatusBadRequest)
+			return
+		}
+	}
+
+	history, err := store.History(world, typeid, q.Get("name"))
+	if err != nil {
+		errLog.Println("HTTP: store.History failed:", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if len(history) == 0 {
+		http.Error(w, "no records", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(renderTrend(history))
+}
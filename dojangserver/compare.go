@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+// maxCompare 는 한 번에 비교할 수 있는 최대 캐릭터 수입니다.
+const maxCompare = 10
+
+// readCompare 함수는 targets 캐릭터들의 주별 기록을 주 단위로 맞춰 비교합니다.
+// 각 주마다 가장 좋은 기록을 낸 캐릭터를 표시하고 그 횟수를 셉니다.
+func readCompare(typeid int, targets []api.CompareTarget) (api.CompareResponse, error) {
+	response := api.CompareResponse{Type: typeid, Characters: targets}
+	weeks := map[string]*api.CompareWeek{}
+	for i := range targets {
+		t := &response.Characters[i]
+		history, err := store.History(t.World, typeid, t.Name)
+		if err != nil {
+			return response, err
+		}
+		t.Found = len(history) > 0
+		for j := range history {
+			key := storage.WeekKey(time.Unix(history[j].CheckedTimeUnix, 0))
+			w, ok := weeks[key]
+			if !ok {
+				w = &api.CompareWeek{Week: key, Records: make([]*api.Record, len(targets))}
+				weeks[key] = w
+			}
+			r := apiRecord(history[j])
+			w.Records[i] = &r
+		}
+	}
+
+	for _, w := range weeks {
+		var best *api.Record
+		present := 0
+		for i, r := range w.Records {
+			if r == nil {
+				continue
+			}
+			present++
+			switch {
+			case best == nil || r.Floor > best.Floor || (r.Floor == best.Floor && r.FullSec() < best.FullSec()):
+				best, w.Best = r, []int{i}
+			case r.Floor == best.Floor && r.FullSec() == best.FullSec():
+				w.Best = append(w.Best, i)
+			}
+		}
+		// 혼자 기록이 있는 주는 비교한 것이 아니므로 세지 않습니다.
+		if present > 1 {
+			for _, i := range w.Best {
+				response.Characters[i].Wins++
+			}
+		}
+		response.Weeks = append(response.Weeks, *w)
+	}
+	sort.Slice(response.Weeks, func(i, j int) bool {
+		return response.Weeks[i].Week < response.Weeks[j].Week
+	})
+	response.Ok = len(response.Weeks) > 0
+	return response, nil
+}
+
+// handleCompare 함수는 GET /compare?c=서버:닉네임&c=...&type= 요청을 처리합니다.
+// 서버는 번호 또는 이름이며, 2명 이상 maxCompare명 이하를 비교할 수 있습니다.
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	typeid := categories()[0]
+	if s := q.Get("type"); s != "" {
+		var err error
+		if typeid, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "invalid type", http.StatusBadRequest)
+			return
+		}
+	}
+
+	pairs := q["c"]
+	if len(pairs) < 2 || len(pairs) > maxCompare {
+		http.Error(w, "give 2 to "+strconv.Itoa(maxCompare)+" characters as c=world:name", http.StatusBadRequest)
+		return
+	}
+	targets := make([]api.CompareTarget, 0, len(pairs))
+	for _, p := range pairs {
+		parts := strings.SplitN(p, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[1]) == "" {
+			http.Error(w, "invalid character "+p, http.StatusBadRequest)
+			return
+		}
+		world, ok := parseWorld(strings.TrimSpace(parts[0]))
+		if !ok {
+			http.Error(w, "unknown world "+parts[0], http.StatusBadRequest)
+			return
+		}
+		targets = append(targets, api.CompareTarget{
+			World:     world,
+			WorldName: serverNameOf(world),
+			Name:      strings.TrimSpace(parts[1]),
+		})
+	}
+
+	response, err := readCompare(typeid, targets)
+	if err != nil {
+		errLog.Println("HTTP: readCompare failed:", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
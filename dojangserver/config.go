@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/robfig/cron"
+)
+
+const defaultRateLimit = 5 // 초당 랭킹 페이지 요청 수
+
+var defaultSchedule = scheduleConfig{
+	ThisWeek: "0 0 7 * * *",
+	LastWeek: "0 0 6 * * Mon",
+	Backup:   "0 30 5 * * *",
+}
+
+type worldConfig struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Enabled *bool  `json:"enabled,omitempty"` // 생략하면 활성화됩니다.
+}
+
+func (c worldConfig) enabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+type categoryConfig struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Enabled *bool  `json:"enabled,omitempty"` // 생략하면 활성화됩니다.
+}
+
+func (c categoryConfig) enabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// scheduleConfig 는 cron 형식(초 분 시 일 월 요일)의 작업 일정입니다.
+type scheduleConfig struct {
+	ThisWeek string `json:"this_week"`
+	LastWeek string `json:"last_week"`
+	Backup   string `json:"backup"`
+}
+
+// config 는 -config 플래그로 읽는 JSON 설정 파일입니다. 생략한 값은 기본값을 사용합니다.
+// 서버는 SIGHUP을 받으면 설정 파일을 다시 읽습니다. 단, addr은 재시작해야 반영됩니다.
+type config struct {
+	Addr       string           `json:"addr"`
+	Worlds     []worldConfig    `json:"worlds"`
+	Categories []categoryConfig `json:"categories"`
+	Schedule   scheduleConfig   `json:"schedule"`
+	RateLimit  float64          `json:"rate_limit"` // 모든 서버를 합친 초당 랭킹 페이지 요청 수
+}
+
+// defaultConfig 함수는 설정 파일이 없을 때의 설정을 반환합니다. enabledCategories에 없는 카테고리는 비활성화됩니다.
+func defaultConfig(enabledCategories []int) *config {
+	cfg := &config{
+		Worlds:    defaultWorlds,
+		Schedule:  defaultSchedule,
+		RateLimit: defaultRateLimit,
+	}
+	for _, c := range defaultCategories {
+		enabled := false
+		for _, typeid := range enabledCategories {
+			enabled = enabled || typeid == c.ID
+		}
+		c.Enabled = &enabled
+		cfg.Categories = append(cfg.Categories, c)
+	}
+	return cfg
+}
+
+// loadConfig 함수는 설정 파일을 읽고 검증합니다.
+func loadConfig(path string) (*config, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config
+	if err := json.Unmarshal(buf, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if cfg.Worlds == nil {
+		cfg.Worlds = defaultWorlds
+	}
+	if cfg.Categories == nil {
+		cfg.Categories = defaultCategories
+	}
+	if cfg.Schedule.ThisWeek == "" {
+		cfg.Schedule.ThisWeek = defaultSchedule.ThisWeek
+	}
+	if cfg.Schedule.LastWeek == "" {
+		cfg.Schedule.LastWeek = defaultSchedule.LastWeek
+	}
+	if cfg.Schedule.Backup == "" {
+		cfg.Schedule.Backup = defaultSchedule.Backup
+	}
+	if cfg.RateLimit == 0 {
+		cfg.RateLimit = defaultRateLimit
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+func (cfg *config) validate() error {
+	seen := map[int]bool{}
+	enabled := 0
+	for _, w := range cfg.Worlds {
+		if seen[w.ID] {
+			return fmt.Errorf("duplicate world %d", w.ID)
+		}
+		seen[w.ID] = true
+		if w.Name == "" {
+			return fmt.Errorf("world %d has no name", w.ID)
+		}
+		if w.enabled() {
+			enabled++
+		}
+	}
+	if enabled == 0 {
+		return fmt.Errorf("no world enabled")
+	}
+
+	seen = map[int]bool{}
+	enabled = 0
+	for _, c := range cfg.Categories {
+		if seen[c.ID] {
+			return fmt.Errorf("duplicate category %d", c.ID)
+		}
+		seen[c.ID] = true
+		if c.Name == "" {
+			return fmt.Errorf("category %d has no name", c.ID)
+		}
+		if c.enabled() {
+			enabled++
+		}
+	}
+	if enabled == 0 {
+		return fmt.Errorf("no category enabled")
+	}
+
+	for _, spec := range []string{cfg.Schedule.ThisWeek, cfg.Schedule.LastWeek, cfg.Schedule.Backup} {
+		if _, err := cron.Parse(spec); err != nil {
+			return fmt.Errorf("schedule %q: %v", spec, err)
+		}
+	}
+	if cfg.RateLimit < 0 {
+		return fmt.Errorf("negative rate_limit")
+	}
+	return nil
+}
+
+// applyConfig 함수는 서버, 카테고리 목록과 요청 속도를 바꾸고 검색 페이지를 다시 만듭니다.
+// 이미 진행 중인 크롤링은 시작할 때의 목록을 계속 사용합니다.
+func applyConfig(cfg *config) {
+	names, list := map[int]string{}, []int{}
+	for _, w := range cfg.Worlds {
+		names[w.ID] = w.Name
+		if w.enabled() {
+			list = append(list, w.ID)
+		}
+	}
+	cnames, clist := map[int]string{}, []int{}
+	for _, c := range cfg.Categories {
+		cnames[c.ID] = c.Name
+		if c.enabled() {
+			clist = append(clist, c.ID)
+		}
+	}
+
+	worldsLock.Lock()
+	serverName, serverList = names, list
+	categoryName, categoryList = cnames, clist
+	worldsLock.Unlock()
+	limiter.SetRate(cfg.RateLimit)
+
+	content := buildWebContent()
+	worldsLock.Lock()
+	cachedWebContent = content
+	worldsLock.Unlock()
+}
+
+var schedulerLock sync.Mutex
+var scheduler *cron.Cron
+var schedulerStopped bool // 종료 중이면 설정을 다시 읽어도 작업을 등록하지 않습니다.
+
+// startScheduler 함수는 실행 중인 cron 실행기를 멈추고 cfg의 일정으로 새 실행기를 시작합니다.
+// robfig/cron은 등록된 작업을 지울 수 없으므로 실행기를 새로 만듭니다. 실행 중인 작업은 중단되지 않습니다.
+func startScheduler(cfg *config) {
+	schedulerLock.Lock()
+	defer schedulerLock.Unlock()
+	if schedulerStopped {
+		return
+	}
+	if scheduler != nil {
+		scheduler.Stop()
+	}
+	scheduler = newScheduler(cfg)
+	scheduler.Start()
+}
+
+// stopScheduler 함수는 cron 실행기를 멈춥니다. 이후에는 다시 시작할 수 없습니다.
+func stopScheduler() {
+	schedulerLock.Lock()
+	defer schedulerLock.Unlock()
+	schedulerStopped = true
+	if scheduler != nil {
+		scheduler.Stop()
+	}
+}
+
+// newScheduler 함수는 설정의 일정대로 작업을 등록한 cron 실행기를 만듭니다.
+func newScheduler(cfg *config) *cron.Cron {
+	c := cron.New()
+	c.AddFunc(cfg.Schedule.ThisWeek, crawlJob)
+	c.AddFunc(cfg.Schedule.LastWeek, crawlJobLastWeek)
+	if *backupDir != "" {
+		c.AddFunc(cfg.Schedule.Backup, backupJob)
+	}
+	return c
+}
+
+// watchReload 함수는 SIGHUP을 받을 때마다 설정 파일을 다시 읽어 적용합니다.
+// 새 설정에 오류가 있으면 이전 설정을 유지합니다.
+func watchReload(path string, cfg *config) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+
+	for range c {
+		verbLog.Println("Config: Reloading", path)
+		next, err := loadConfig(path)
+		if err != nil {
+			errLog.Println("Config: Reload failed, keeping previous config:", err)
+			notifier.Notify("설정 다시 읽기 실패: " + err.Error())
+			continue
+		}
+		if next.Addr == "" {
+			next.Addr = cfg.Addr
+		}
+		if next.Addr != cfg.Addr {
+			warnLog.Printf("Config: addr changed from %s to %s, restart to apply", cfg.Addr, next.Addr)
+			next.Addr = cfg.Addr
+		}
+
+		applyConfig(next)
+		startScheduler(next)
+
+		cfg = next
+		verbLog.Printf("Config: Reloaded (%d worlds, %d categories)", len(servers()), len(categories()))
+		notifier.Notify(fmt.Sprintf("설정을 다시 읽었습니다: 서버 %d개, 카테고리 %d개", len(servers()), len(categories())))
+	}
+}
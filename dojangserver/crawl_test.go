@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/fakenexon"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+// setupTest 함수는 기본 설정을 적용하고 임시 DB를 store로 엽니다.
+// 크롤러는 fake 서버에서 지연 없이 수집하도록 바꾸며, 테스트가 끝나면 모두 되돌립니다.
+func setupTest(t *testing.T, fake *fakenexon.Server) {
+	t.Helper()
+	applyConfig(defaultConfig([]int{2}))
+
+	oldSource, oldLimiter, oldBackoff, oldStore := source, limiter, crawlInitialBackoff, store
+	source = &nexonSource{BaseURL: fake.URL}
+	limiter = newTokenBucket(1000, 1000)
+	crawlInitialBackoff = time.Millisecond
+
+	var err error
+	if store, err = storage.Open(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		store.Close()
+		fake.Close()
+		source, limiter, crawlInitialBackoff, store = oldSource, oldLimiter, oldBackoff, oldStore
+	})
+}
+
+func TestCrawlDojangRank(t *testing.T) {
+	page := func(next int, names ...string) string {
+		items := make([]string, len(names))
+		for i, name := range names {
+			items[i] = `{"rank":"1","nick":"` + name + `","floor":"50층","duration":"10분 0초","guild_worldid":"1"}`
+		}
+		return `{"result":"S","list":[` + strings.Join(items, ",") + `],"nextidx":"` + strconv.Itoa(next) + `"}`
+	}
+
+	tests := []struct {
+		name      string
+		responses map[int][]fakenexon.Response // rankidx별 응답
+		records   int
+		pages     int
+		retries   int
+		failures  int
+		err       string // 비어 있으면 오류가 없어야 합니다.
+	}{
+		{
+			name: "normal",
+			responses: map[int][]fakenexon.Response{
+				1: {{Body: page(3, "a", "b")}},
+				3: {{Body: page(4, "c")}},
+			},
+			records: 3, pages: 3,
+		},
+		{
+			name: "malformed page is retried",
+			responses: map[int][]fakenexon.Response{
+				1: {{Body: fakenexon.MalformedPage}, {Body: fakenexon.MalformedPage}, {Body: page(2, "a")}},
+			},
+			records: 1, pages: 2, retries: 2,
+		},
+		{
+			name: "500 keeps records of earlier pages",
+			responses: map[int][]fakenexon.Response{
+				1: {{Body: page(3, "a", "b")}},
+				3: {{Status: 500, Body: "Internal Server Error"}},
+			},
+			records: 2, pages: 1, retries: crawlMaxRetries, failures: 1,
+			err: "rankidx=3: unexpected HTTP status 500",
+		},
+		{
+			name:    "empty last page",
+			records: 0, pages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := fakenexon.NewEmptyServer()
+			setupTest(t, fake)
+			for idx, resp := range tt.responses {
+				fake.Set(fakenexon.Key{World: 1, Type: 2, RankIdx: idx}, resp...)
+			}
+
+			ranks, report, err := crawlDojangRank(context.Background(), 1, 2, false)
+			if tt.err == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+				t.Fatalf("error = %v, want %q", err, tt.err)
+			}
+			if len(ranks) != tt.records || report.Records != tt.records {
+				t.Errorf("records = %d (report %d), want %d", len(ranks), report.Records, tt.records)
+			}
+			if report.Pages != tt.pages || report.Retries != tt.retries || report.Failures != tt.failures {
+				t.Errorf("report = %+v, want pages %d, retries %d, failures %d", report, tt.pages, tt.retries, tt.failures)
+			}
+		})
+	}
+}
+
+func TestCrawlDojangRankCancelled(t *testing.T) {
+	fake := fakenexon.NewEmptyServer()
+	setupTest(t, fake)
+	fake.Set(fakenexon.Key{World: 1, Type: 2, RankIdx: 1}, fakenexon.Response{Status: 500})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := crawlDojangRank(ctx, 1, 2, false); err == nil {
+		t.Fatal("crawl with cancelled context succeeded")
+	}
+	if hits := fake.Hits(fakenexon.Key{World: 1, Type: 2, RankIdx: 1}); hits != 0 {
+		t.Errorf("cancelled crawl sent %d requests, want 0", hits)
+	}
+}
+
+func TestCrawlDojangRankCancelledDuringBackoff(t *testing.T) {
+	fake := fakenexon.NewEmptyServer()
+	setupTest(t, fake)
+	fake.Set(fakenexon.Key{World: 1, Type: 2, RankIdx: 1}, fakenexon.Response{Status: 500})
+	crawlInitialBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	done := make(chan error, 1)
+	var report crawlReport
+	go func() {
+		var err error
+		_, report, err = crawlDojangRank(ctx, 1, 2, false)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("crawl kept retrying after its context was cancelled")
+	}
+	if report.Retries != 1 {
+		t.Errorf("retries = %d, want 1", report.Retries)
+	}
+	if hits := fake.Hits(fakenexon.Key{World: 1, Type: 2, RankIdx: 1}); hits != 1 {
+		t.Errorf("requests = %d, want 1", hits)
+	}
+}
+
+func TestFetchPageCancelled(t *testing.T) {
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer stalled.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := (&nexonSource{BaseURL: stalled.URL}).FetchPage(ctx, 1, 2, false, 1)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("FetchPage on a stalled server succeeded")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("FetchPage did not return after its context was cancelled")
+	}
+}
+
+func TestCrawlWorld(t *testing.T) {
+	fake := fakenexon.NewServer()
+	setupTest(t, fake)
+
+	now := time.Date(2018, 3, 7, 7, 0, 0, 0, time.Local) // 수요일
+	crawlWorld(context.Background(), 1, 2, false, now)
+	crawlWorld(context.Background(), 12, 2, false, now)
+
+	for _, tt := range []struct {
+		world int
+		name  string
+		floor int
+	}{
+		{1, "무릉고수", 61},
+		{1, "bishop", 61},
+		{1, "활쟁이", 55},
+		{12, "표도", 63},
+	} {
+		c, err := store.GetCharacter(tt.world, 2, tt.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c == nil {
+			t.Errorf("%s(%d) was not stored", tt.name, tt.world)
+			continue
+		}
+		if c.Recent.Floor != tt.floor || c.Max.Floor != tt.floor {
+			t.Errorf("%s: floor = %d/%d, want %d", tt.name, c.Recent.Floor, c.Max.Floor, tt.floor)
+		}
+	}
+	if hits := fake.Hits(fakenexon.Key{World: 12, Type: 2, RankIdx: 1}); hits != 3 {
+		t.Errorf("리부트2 first page hits = %d, want 3", hits)
+	}
+}
+
+func TestCrawlWorldPartialIsFailure(t *testing.T) {
+	fake := fakenexon.NewEmptyServer()
+	setupTest(t, fake)
+	fake.Set(fakenexon.Key{World: 1, Type: 2, RankIdx: 1}, fakenexon.Recorded[fakenexon.Key{World: 1, Type: 2, RankIdx: 1}]...)
+	fake.Set(fakenexon.Key{World: 1, Type: 2, RankIdx: 3}, fakenexon.Response{Status: 500})
+	fake.Set(fakenexon.Key{World: 12, Type: 2, RankIdx: 1}, fakenexon.Recorded[fakenexon.Key{World: 12, Type: 2, RankIdx: 1}]...)
+
+	metrics.Lock()
+	metrics.crawls = map[crawlKey]*crawlMetrics{}
+	metrics.Unlock()
+
+	now := time.Date(2018, 3, 7, 7, 0, 0, 0, time.Local)
+	crawlWorld(context.Background(), 1, 2, false, now)
+	crawlWorld(context.Background(), 12, 2, false, now)
+
+	// 실패 전에 받은 기록은 저장됩니다.
+	if c, err := store.GetCharacter(1, 2, "무릉고수"); err != nil || c == nil {
+		t.Fatalf("partial results were not stored: %v, %v", c, err)
+	}
+
+	metrics.Lock()
+	defer metrics.Unlock()
+	partial := metrics.crawls[crawlKey{1, 2, false}]
+	if partial.LastSuccess != 0 || partial.Failures != 1 {
+		t.Errorf("partial crawl: LastSuccess = %d, Failures = %d, want 0, 1", partial.LastSuccess, partial.Failures)
+	}
+	full := metrics.crawls[crawlKey{12, 2, false}]
+	if full.LastSuccess == 0 || full.Failures != 0 {
+		t.Errorf("complete crawl: LastSuccess = %d, Failures = %d, want set, 0", full.LastSuccess, full.Failures)
+	}
+}
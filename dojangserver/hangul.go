@@ -0,0 +1,67 @@
+package main
+
+const (
+	hangulBase  = 0xAC00
+	hangulLast  = 0xD7A3
+	jamoInitial = 0x1100
+	jamoMedial  = 0x1161
+	jamoFinal   = 0x11A7
+)
+
+// decomposeHangul 함수는 한글 음절을 초성, 중성, 종성 자모로 분해합니다.
+// 한글 음절이 아닌 문자는 그대로 둡니다. 받침이 없는 음절은 종성을 생략합니다.
+func decomposeHangul(s string) []rune {
+	ret := make([]rune, 0, len(s))
+	for _, r := range s {
+		if r < hangulBase || r > hangulLast {
+			ret = append(ret, r)
+			continue
+		}
+		idx := r - hangulBase
+		ret = append(ret, jamoInitial+idx/588, jamoMedial+(idx%588)/28)
+		if final := idx % 28; final != 0 {
+			ret = append(ret, jamoFinal+final)
+		}
+	}
+	return ret
+}
+
+// editDistance 함수는 두 룬 배열의 레벤슈타인 거리를 반환합니다.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// hasRunePrefix 함수는 s가 prefix로 시작하는지 반환합니다.
+func hasRunePrefix(s, prefix []rune) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cr0sh/dojangsearch/api"
+)
+
+func handleHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	var request api.CharacterRequest
+	dec := json.NewDecoder(r.Body)
+
+	if err := dec.Decode(&request); err != nil {
+		errLog.Println("HTTP: Request parse failed:", err)
+		return
+	}
+
+	var response api.HistoryResponse
+
+	meta, err := store.Meta(request.World, request.Type)
+	if err != nil {
+		errLog.Println("HTTP: store.Meta failed:", err)
+		return
+	}
+	response.Start, response.End = meta.Start, meta.End
+
+	history, err := store.History(request.World, request.Type, request.Name)
+	if err != nil {
+		errLog.Println("HTTP: store.History failed:", err)
+		return
+	}
+	response.History = apiRecords(history)
+	response.Ok = len(response.History) > 0
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/fakenexon"
+)
+
+// crawlTestData 함수는 녹화된 이번 주, 지난주 랭킹을 임시 DB에 수집합니다.
+func crawlTestData(t *testing.T) {
+	t.Helper()
+	setupTest(t, fakenexon.NewServer())
+	now := time.Date(2018, 3, 7, 7, 0, 0, 0, time.Local) // 수요일
+	crawlWorld(context.Background(), 1, 2, true, now)
+	crawlWorld(context.Background(), 1, 2, false, now)
+}
+
+func postJSON(t *testing.T, h http.HandlerFunc, path, body string, v interface{}) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("%s: %v (body %q)", path, err, w.Body.String())
+		}
+	}
+	return w.Code
+}
+
+func TestHandleGetRank(t *testing.T) {
+	crawlTestData(t)
+
+	var resp api.RankResponse
+	if code := postJSON(t, handleGetRank, "/getrank", `{"World":1,"Type":2,"Name":"무릉고수"}`, &resp); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if !resp.Ok || resp.ID == 0 {
+		t.Fatalf("response = %+v, want Ok with ID", resp)
+	}
+	if resp.Rank.Floor != 61 || resp.MRank.Floor != 61 {
+		t.Errorf("recent/max floor = %d/%d, want 61/61", resp.Rank.Floor, resp.MRank.Floor)
+	}
+	if resp.Start == 0 || resp.End == 0 {
+		t.Errorf("collection period is empty: %d ~ %d", resp.Start, resp.End)
+	}
+	if resp.Weekly == nil || resp.Weekly.Rank != 1 || resp.Weekly.Total != 3 {
+		t.Errorf("weekly context = %+v, want rank 1 of 3", resp.Weekly)
+	}
+
+	resp = api.RankResponse{}
+	postJSON(t, handleGetRank, "/getrank", `{"World":1,"Type":2,"Name":"없는캐릭터"}`, &resp)
+	if resp.Ok {
+		t.Errorf("unknown character: Ok = true")
+	}
+}
+
+func TestHandleHistory(t *testing.T) {
+	crawlTestData(t)
+
+	var resp api.HistoryResponse
+	if code := postJSON(t, handleHistory, "/history", `{"World":1,"Type":2,"Name":"무릉고수"}`, &resp); code != http.StatusOK {
+		t.Fatalf("status = %d", code)
+	}
+	if !resp.Ok || len(resp.History) != 2 {
+		t.Fatalf("history = %+v, want 2 weeks", resp.History)
+	}
+	if resp.History[0].Floor != 60 || resp.History[1].Floor != 61 {
+		t.Errorf("floors = %d, %d, want 60, 61 (oldest first)", resp.History[0].Floor, resp.History[1].Floor)
+	}
+
+	resp = api.HistoryResponse{}
+	postJSON(t, handleHistory, "/history", `{"World":1,"Type":2,"Name":"Bishop"}`, &resp)
+	if !resp.Ok || len(resp.History) != 1 {
+		t.Errorf("Bishop history = %+v, want 1 week", resp.History)
+	}
+}
+
+func TestHandlersDBError(t *testing.T) {
+	crawlTestData(t)
+
+	tests := []struct {
+		path string
+		h    http.HandlerFunc
+	}{
+		{"/leaderboard?world=1&type=2", handleLeaderboard},
+		{"/search?q=" + url.QueryEscape("무릉"), handleSearch},
+		{"/stats/jobs?world=1&type=2", handleJobStats},
+	}
+	get := func(path string, h http.HandlerFunc) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	for _, tt := range tests {
+		if w := get(tt.path, tt.h); w.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d before closing the DB (body %q)", tt.path, w.Code, w.Body.String())
+		}
+	}
+
+	// DB 오류는 빈 결과와 구분되도록 500으로 응답해야 합니다.
+	store.Close()
+	for _, tt := range tests {
+		if w := get(tt.path, tt.h); w.Code != http.StatusInternalServerError {
+			t.Errorf("%s: status = %d, want 500 (body %q)", tt.path, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+const defaultLeaderboardSize = 100
+const maxLeaderboardSize = 1000
+
+// readLeaderboard 함수는 한 서버의 기록을 정렬하여 상위 n개를 반환합니다.
+// week가 비어 있으면 최고 기록을, 아니라면 해당 주의 기록을 사용합니다.
+func readLeaderboard(world, typeid int, week string, job string, n int) ([]storage.Record, error) {
+	ranks := make([]storage.Record, 0, n)
+	collect := func(rank storage.Record) error {
+		if job != "" && rank.Job != job && rank.DetailJob != job {
+			return nil
+		}
+		ranks = append(ranks, rank)
+		return nil
+	}
+
+	var err error
+	if week == "" {
+		err = store.ForEachMax(world, typeid, collect)
+	} else {
+		err = store.ForEachWeek(world, typeid, week, collect)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ranks, func(i, j int) bool {
+		return storage.Better(ranks[i], ranks[j])
+	})
+	if len(ranks) > n {
+		ranks = ranks[:n]
+	}
+	return ranks, nil
+}
+
+func handleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	worlds := servers()
+	if s := q.Get("world"); s != "" {
+		world, err := strconv.Atoi(s)
+		if err != nil {
+			http.Error(w, "invalid world", http.StatusBadRequest)
+			return
+		}
+		worlds = []int{world}
+	}
+
+	typeid := categories()[0]
+	if s := q.Get("type"); s != "" {
+		var err error
+		if typeid, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "invalid type", http.StatusBadRequest)
+			return
+		}
+	}
+
+	n := defaultLeaderboardSize
+	if s := q.Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n <= 0 {
+			http.Error(w, "invalid n", http.StatusBadRequest)
+			return
+		}
+		if n > maxLeaderboardSize {
+			n = maxLeaderboardSize
+		}
+	}
+
+	// week 파라미터가 없거나 all 이면 전체 기간 최고 기록을 보여줍니다.
+	week := q.Get("week")
+	if week == "all" {
+		week = ""
+	}
+
+	var response api.LeaderboardResponse
+	response.Type, response.Week = typeid, week
+
+	for _, world := range worlds {
+		ranks, err := readLeaderboard(world, typeid, week, q.Get("job"), n)
+		if err != nil {
+			errLog.Println("HTTP: readLeaderboard failed:", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		response.Worlds = append(response.Worlds, api.Leaderboard{
+			World: world,
+			Name:  serverNameOf(world),
+			Ranks: apiRecords(ranks),
+		})
+	}
+	response.Ok = true
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
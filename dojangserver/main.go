@@ -1,26 +1,20 @@
 package main
 
 import (
-	"encoding/binary"
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
-	"github.com/boltdb/bolt"
-	"github.com/robfig/cron"
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/fakenexon"
+	"github.com/cr0sh/dojangsearch/storage"
 	"github.com/tucnak/telebot"
-	"io"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
-	"strconv"
-	"strings"
 	"sync"
+	"syscall"
 	"time"
-	"unicode"
-	"unsafe"
 )
 
 const timeFormat = "2006-01-02 15:04:05"
@@ -34,59 +28,52 @@ var lastCrawlTime int64
 var lastCrawlTimeLockLastWeek sync.Mutex
 var lastCrawlTimeLastWeek int64
 
-var db *bolt.DB
+var store *storage.Store
 
 var bot *telebot.Bot
 var channel *telebot.Chat
 
 var token, clientID *string
 
-type rankItem struct {
-	Rank       int64  `json:"rank,string"`
-	Move       int64  `json:"move,string"`
-	IconURL    string `json:"icon"`
-	Name       string `json:"nick"`
-	Job        string `json:"job"`
-	DetailJob  string `json:"detail_job"`
-	Level      int64  `json:"level"`
-	Exp        int64  `json:"exp"`
-	Popularity int64  `json:"popular"`
-	FloorStr   string `json:"floor"`
-	Duration   string `json:"duration"`
-	GuildID    int64  `json:"guild_worldid,string"` // ?
-
-	Second          int   `json:"second,omitempty"`
-	Minute          int   `json:"minute,omitempty"`
-	World           int   `json:"world,omitempty"`
-	Floor           int   `json:"rawfloor,omitempty"`
-	Type            int   `json:"type,omitempty"`
-	CheckedTimeUnix int64 `json:"checkedtime,omitempty"`
-}
-
-func (r *rankItem) fullsec() int {
-	return r.Second + r.Minute*60
+// crawlCtx 는 서버가 종료될 때 취소되어 진행 중인 크롤링을 멈춥니다.
+// crawlLock 은 crawlCtx 확인과 crawlWG.Add, 취소를 묶어 종료 중에 새 작업이 추가되지 않도록 합니다.
+var crawlCtx, cancelCrawls = context.WithCancel(context.Background())
+var crawlWG sync.WaitGroup // 진행 중인 크롤링 작업
+var crawlLock sync.Mutex
+
+// beginCrawl 함수는 서버가 종료 중이 아니라면 crawlWG에 작업을 추가하고 true를 반환합니다.
+// true를 반환했다면 작업이 끝난 뒤 crawlWG.Done을 호출해야 합니다.
+func beginCrawl() bool {
+	crawlLock.Lock()
+	defer crawlLock.Unlock()
+	if crawlCtx.Err() != nil {
+		return false
+	}
+	crawlWG.Add(1)
+	return true
 }
 
-// alignTime 함수는 해당 날짜에서 전 주의 일요일을 반환합니다. 시, 분, 초는 입력과 같습니다.
-// 각 주는 월요일이 시작입니다.
-func alignTime(t time.Time) time.Time {
-	weekday := t.Weekday()
-	if weekday == time.Sunday {
-		return t.AddDate(0, 0, -7)
-	} else {
-		return t.AddDate(0, 0, -int(weekday))
-	}
+// stopCrawls 함수는 진행 중인 크롤링을 취소합니다. 이후 beginCrawl은 항상 false를 반환합니다.
+func stopCrawls() {
+	crawlLock.Lock()
+	defer crawlLock.Unlock()
+	cancelCrawls()
 }
 
 func crawlJob() {
-	bot.Send(channel, "*크롤링 작업이 시작됩니다.")
+	notifier.Notify("*크롤링 작업이 시작됩니다.")
 	lastCrawlTimeLock.Lock()
 	if lastCrawlTime != 0 {
 		errLog.Println("Crawler: Another crawler is already running since", time.Unix(lastCrawlTime, 0).Format(timeFormat))
-		bot.Send(channel, "경고: 크롤러 작업이 지연중입니다")
+		notifier.Notify("경고: 크롤러 작업이 지연중입니다")
 		lastCrawlTimeLock.Unlock()
 		return
 	}
+	if !beginCrawl() {
+		lastCrawlTimeLock.Unlock()
+		return
+	}
+	defer crawlWG.Done()
 	now := time.Now()
 	lastCrawlTime = now.Unix()
 	verbLog.Println("Crawler: Started ranking crawler at", now.Format(timeFormat))
@@ -94,47 +81,30 @@ func crawlJob() {
 	defer func() {
 		lastCrawlTimeLock.Lock()
 		verbLog.Println("Crawler: Finished ranking crawler since", time.Unix(lastCrawlTime, 0).Format(timeFormat), "at", time.Now().Format(timeFormat))
-		bot.Send(channel, "*크롤링 작업이 종료됩니다.")
+		notifier.Notify("*크롤링 작업이 종료됩니다.")
 		lastCrawlTime = 0
 		lastCrawlTimeLock.Unlock()
 	}()
 
-	rankss := make([][]rankItem, len(serverList))
-	for i, world := range serverList {
-		verbLog.Println("Crawler: Starting HTTP client for", serverName[world])
-		ranks, err := crawlDojangRank(world, 2, false)
-		if err != nil {
-			errLog.Println("Crawler: crawlDojangRank failed:", err)
-			bot.Send(channel, fmt.Sprintf("%s 크롤링 오류: %s", serverName[world], err.Error()))
-			continue
-		}
-		rankss[i] = ranks
-	}
-
-	for i, world := range serverList {
-		if rankss[i] == nil {
-			continue
-		}
-		bot.Send(channel, fmt.Sprintf("%s DB 갱신중: 기록 %d개", serverName[world], len(rankss[i])))
-		verbLog.Printf("Crawler: Updating database for %s (%d items)", serverName[world], len(rankss[i]))
-		if err := updateDatabase(world, 2, rankss[i], now); err != nil {
-			errLog.Println("Crawler: Error while boltDB update Transaction:", err)
-			bot.Send(channel, fmt.Sprintf("%s DB 갱신 오류: %s", serverName[world], err.Error()))
-		}
-	}
+	crawlAll(now, false)
 
-	bot.Send(channel, "지난주 크롤링 작업이 정상입니다.")
+	notifier.Notify("이번주 크롤링 작업이 정상입니다.")
 }
 
 func crawlJobLastWeek() {
-	bot.Send(channel, "*지난주 크롤링 작업이 시작됩니다.")
+	notifier.Notify("*지난주 크롤링 작업이 시작됩니다.")
 	lastCrawlTimeLockLastWeek.Lock()
 	if lastCrawlTimeLastWeek != 0 {
 		errLog.Println("Crawler: Another crawler is already running since", time.Unix(lastCrawlTimeLastWeek, 0).Format(timeFormat))
-		bot.Send(channel, "경고: 지난주 크롤러 작업이 지연중입니다")
+		notifier.Notify("경고: 지난주 크롤러 작업이 지연중입니다")
 		lastCrawlTimeLockLastWeek.Unlock()
 		return
 	}
+	if !beginCrawl() {
+		lastCrawlTimeLockLastWeek.Unlock()
+		return
+	}
+	defer crawlWG.Done()
 	now := time.Now()
 	lastCrawlTimeLastWeek = now.Unix()
 	verbLog.Println("Crawler: Started lastweek ranking crawler at", now.Format(timeFormat))
@@ -142,339 +112,252 @@ func crawlJobLastWeek() {
 	defer func() {
 		lastCrawlTimeLockLastWeek.Lock()
 		verbLog.Println("Crawler: Finished lastweek ranking crawler since", time.Unix(lastCrawlTimeLastWeek, 0).Format(timeFormat), "at", time.Now().Format(timeFormat))
-		bot.Send(channel, "*지난주 크롤링 작업이 종료됩니다.")
+		notifier.Notify("*지난주 크롤링 작업이 종료됩니다.")
 		lastCrawlTimeLastWeek = 0
 		lastCrawlTimeLockLastWeek.Unlock()
 	}()
 
-	rankss := make([][]rankItem, len(serverList))
-	for i, world := range serverList {
-		verbLog.Println("Crawler: Starting HTTP client for", serverName[world])
-		ranks, err := crawlDojangRank(world, 2, true)
-		if err != nil {
-			errLog.Println("Crawler: crawlDojangRankLastWeek failed:", err)
-			bot.Send(channel, fmt.Sprintf("%s 지난주 크롤링 오류: %s", serverName[world], err.Error()))
-			continue
-		}
-		rankss[i] = ranks
-	}
-
-	for i, world := range serverList {
-		if rankss[i] == nil {
-			continue
-		}
-		bot.Send(channel, fmt.Sprintf("%s 지난주 DB 갱신중: 기록 %d개", serverName[world], len(rankss[i])))
-		verbLog.Printf("Crawler: Updating lastweek database for %s (%d items)", serverName[world], len(rankss[i]))
-		if err := updateDatabaseLastWeek(world, 2, rankss[i], now); err != nil {
-			errLog.Println("Crawler: Error while boltDB update Transaction:", err)
-			bot.Send(channel, fmt.Sprintf("%s 지난주 DB 갱신 오류: %s", serverName[world], err.Error()))
-		}
-	}
+	crawlAll(now, true)
 
-	bot.Send(channel, "지난주 크롤링 작업이 정상입니다.")
+	notifier.Notify("지난주 크롤링 작업이 정상입니다.")
 }
 
-func crawlDojangRank(world, typeid int, lastWeek bool) ([]rankItem, error) {
-	idx := 1
-	ranks := make([]rankItem, 0, 200)
-	t := time.NewTicker(time.Millisecond * 200)
-	defer t.Stop()
-
-	for range t.C {
-		var u *url.URL
-		if lastWeek {
-			u, _ = url.Parse("http://m.maplestory.nexon.com/MapleStory/Data/Json/Ranking/DojangLastWeekListJson.aspx")
-		} else {
-			u, _ = url.Parse("http://m.maplestory.nexon.com/MapleStory/Data/Json/Ranking/DojangThisWeekListJson.aspx")
-		}
-		q := u.Query()
-		q.Add("rankidx", strconv.Itoa(idx))
-		q.Add("cateType", strconv.Itoa(typeid))
-		q.Add("GameWorldID", strconv.Itoa(world))
-		u.RawQuery = q.Encode()
-		r, err := http.Get(u.String())
-		if r != nil {
-			defer r.Body.Close()
-		}
-
-		if err != nil {
-			return nil, err
-		}
-
-		var resp struct {
-			Result  string     `json:"result"`
-			List    []rankItem `json:"list"`
-			NextIdx int        `json:"nextidx,string"`
-		}
-
-		dec := json.NewDecoder(r.Body)
-		dec.Decode(&resp)
-		if len(resp.List) == 0 {
-			break
+// crawlAll 함수는 모든 서버, 카테고리를 동시에 수집합니다. 요청 속도는 limiter가 전체적으로 제한하며,
+// 설정을 다시 읽더라도 이번 작업은 시작할 때의 목록을 사용합니다.
+func crawlAll(now time.Time, lastWeek bool) {
+	var wg sync.WaitGroup
+	worlds := servers()
+	for _, typeid := range categories() {
+		for _, world := range worlds {
+			wg.Add(1)
+			go func(world, typeid int) {
+				defer wg.Done()
+				crawlWorld(crawlCtx, world, typeid, lastWeek, now)
+			}(world, typeid)
 		}
-		ranks = append(ranks, resp.List...)
-		idx = resp.NextIdx
-
-		io.Copy(ioutil.Discard, r.Body)
 	}
-	return ranks, nil
+	wg.Wait()
 }
 
-func updateDatabase(world, typeid int, ranks []rankItem, updateTime time.Time) error {
-	realTime := updateTime
-	if updateTime.Weekday() != time.Monday {
-		realTime.AddDate(0, 0, -1)
+// crawlWorld 함수는 한 서버, 카테고리를 수집하고 끝나는 대로 DB에 저장합니다.
+// 수집 도중 실패하더라도 그때까지 받은 기록은 저장하지만, ctx가 취소된 경우에는 저장하지 않습니다.
+func crawlWorld(ctx context.Context, world, typeid int, lastWeek bool, now time.Time) {
+	prefix, align := "", storage.WeekAlign(storage.ThisWeek)
+	if lastWeek {
+		prefix, align = "지난주 ", storage.LastWeek
 	}
-	return db.Update(func(tx *bolt.Tx) error {
-		br, err := tx.CreateBucketIfNotExists([]byte("recent-" + strconv.Itoa(world) + "-" + strconv.Itoa(typeid)))
-		if err != nil {
-			return err
-		}
-
-		bm, err := tx.CreateBucketIfNotExists([]byte("maxrecord-" + strconv.Itoa(world) + "-" + strconv.Itoa(typeid)))
-		if err != nil {
-			return err
-		}
-
-		bmeta, err := tx.CreateBucketIfNotExists([]byte("metadata-" + strconv.Itoa(world) + "-" + strconv.Itoa(typeid)))
-		if err != nil {
-			return err
+	name := fmt.Sprintf("%s(%s)", serverNameOf(world), categoryNameOf(typeid))
+
+	verbLog.Println("Crawler: Starting HTTP client for", serverNameOf(world), categoryNameOf(typeid))
+	started := time.Now()
+	ranks, report, err := crawlDojangRank(ctx, world, typeid, lastWeek)
+	observeCrawl(report, started)
+	if ctx.Err() != nil {
+		verbLog.Printf("Crawler: Cancelled %s%s after %d records", prefix, name, len(ranks))
+		return
+	}
+	verbLog.Println("Crawler:", report)
+	notifier.Notify(report.String())
+	if err != nil {
+		errLog.Printf("Crawler: crawlDojangRank failed for %s%s: %v", prefix, name, err)
+		notifier.Notify(fmt.Sprintf("%s %s크롤링 오류: %s", name, prefix, err.Error()))
+		if len(ranks) == 0 {
+			observeResult(world, typeid, lastWeek, err)
+			return
 		}
-		for _, rank := range ranks {
-			dur := []rune(rank.Duration)
-			fl := []rune(rank.FloorStr)
-			idx := 0
-			for _, d := range dur {
-				if unicode.IsNumber(d) {
-					idx++
-				} else {
-					break
-				}
-			}
-
-			idx2 := 0
-			for _, f := range fl {
-				if unicode.IsNumber(f) {
-					idx2++
-				} else {
-					break
-				}
-			}
-
-			var err error
-			if rank.Minute, err = strconv.Atoi(string(dur[:idx])); err != nil {
-				return err
-			}
-			if rank.Second, err = strconv.Atoi(string(dur[idx+2 : len(dur)-1])); err != nil {
-				return err
-			}
-			if rank.Floor, err = strconv.Atoi(string(fl[:idx2])); err != nil {
-				return err
-			}
-			rank.CheckedTimeUnix = realTime.Unix()
+	}
+	crawlErr := err
 
-			buf, err := json.Marshal(rank)
-			if err != nil {
-				return err
-			}
+	notifier.Notify(fmt.Sprintf("%s %sDB 갱신중: 기록 %d개", name, prefix, len(ranks)))
+	verbLog.Printf("Crawler: Updating %sdatabase for %s (%d items)", prefix, name, len(ranks))
+	records, skipped := parseRecords(world, ranks)
+	if len(skipped) > 0 {
+		notifier.Notify(fmt.Sprintf("%s %s기록 %d개 파싱 실패 (예: %s)", name, prefix, len(skipped), skipped[0].Error()))
+	}
+	started = time.Now()
+	err = store.PutWeekResults(world, typeid, records, now, align)
+	observeUpdate(world, typeid, lastWeek, len(skipped), time.Since(started))
+	invalidateDist(world, typeid)
+	if err != nil {
+		errLog.Println("Crawler: Error while boltDB update Transaction:", err)
+		notifier.Notify(fmt.Sprintf("%s %sDB 갱신 오류: %s", name, prefix, err.Error()))
+		observeResult(world, typeid, lastWeek, err)
+		return
+	}
+	// 일부 페이지만 받았다면 저장은 했더라도 최신 데이터가 아니므로 실패로 기록합니다.
+	observeResult(world, typeid, lastWeek, crawlErr)
+}
 
-			mbuf := bm.Get([]byte(rank.Name))
-			if mbuf == nil {
-				bm.Put([]byte(strings.ToLower(rank.Name)), buf)
-				br.Put([]byte(strings.ToLower(rank.Name)), buf)
-				continue
-			}
+const crawlMaxRetries = 5
+const crawlMaxBackoff = time.Second * 30
+
+// crawlInitialBackoff 는 첫 재시도 전 대기 시간입니다. 테스트에서는 짧게 줄입니다.
+var crawlInitialBackoff = time.Second
+
+// crawlReport 는 한 서버의 랭킹 수집 결과를 요약합니다.
+type crawlReport struct {
+	World    int
+	Type     int
+	LastWeek bool
+	Pages    int // 정상적으로 받은 페이지 수
+	Records  int
+	Retries  int
+	Failures int // 재시도 끝에 포기한 페이지 수
+}
 
-			var mrank rankItem
-			if err := json.Unmarshal(mbuf, &mrank); err != nil {
-				return err
-			}
+func (r crawlReport) String() string {
+	prefix := ""
+	if r.LastWeek {
+		prefix = "지난주 "
+	}
+	return fmt.Sprintf("%s(%s) %s수집 결과: 페이지 %d개, 기록 %d개, 재시도 %d회, 실패 %d회",
+		serverNameOf(r.World), categoryNameOf(r.Type), prefix, r.Pages, r.Records, r.Retries, r.Failures)
+}
 
-			rbuf := br.Get([]byte(rank.Name))
-			if rbuf != nil {
-				var rrank rankItem
-				if err := json.Unmarshal(rbuf, &rrank); err != nil {
-					return err
+// crawlDojangRank 함수는 한 서버의 랭킹을 모두 수집합니다. 각 페이지는 지수 백오프로 재시도되며,
+// 재시도 중에는 마지막으로 성공한 nextidx부터 다시 요청합니다.
+// 재시도 끝에 실패하거나 ctx가 취소되더라도 그때까지 수집한 기록은 오류와 함께 반환됩니다.
+// 모든 요청은 limiter의 토큰을 얻은 뒤에 보냅니다.
+func crawlDojangRank(ctx context.Context, world, typeid int, lastWeek bool) ([]storage.Record, crawlReport, error) {
+	idx := 1
+	ranks := make([]storage.Record, 0, 200)
+	report := crawlReport{World: world, Type: typeid, LastWeek: lastWeek}
+
+	for {
+		var resp *rankPage
+		var err error
+		backoff := crawlInitialBackoff
+		for try := 0; try <= crawlMaxRetries; try++ {
+			if try > 0 {
+				report.Retries++
+				warnLog.Printf("Crawler: Retrying %s rankidx=%d in %v (%v)", serverNameOf(world), idx, backoff, err)
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					report.Records = len(ranks)
+					return ranks, report, ctx.Err()
 				}
-
-				ryear, rweek := time.Unix(rrank.CheckedTimeUnix, 0).ISOWeek()
-				cyear, cweek := realTime.ISOWeek()
-				if ryear == cyear && rweek == cweek &&
-					rrank.Floor == rank.Floor && rrank.fullsec() == rank.fullsec() {
-					continue
+				if backoff *= 2; backoff > crawlMaxBackoff {
+					backoff = crawlMaxBackoff
 				}
 			}
-
-			br.Put([]byte(strings.ToLower(rank.Name)), buf)
-
-			if mrank.Floor < rank.Floor || (mrank.Floor == rank.Floor && mrank.fullsec() > rank.fullsec()) {
-				bm.Put([]byte(strings.ToLower(rank.Name)), buf)
+			if err = limiter.Wait(ctx); err != nil {
+				report.Records = len(ranks)
+				return ranks, report, err
+			}
+			if resp, err = source.FetchPage(ctx, world, typeid, lastWeek, idx); err == nil {
+				break
 			}
 		}
-
-		ntime := updateTime.Unix()
-		buf := make([]byte, 8)
-		binary.BigEndian.PutUint64(buf, *(*uint64)(unsafe.Pointer(&ntime)))
-
-		startbuf := bmeta.Get([]byte("start"))
-		if startbuf == nil {
-			bmeta.Put([]byte("start"), buf)
-		}
-		bmeta.Put([]byte("end"), buf)
-		return nil
-	})
-}
-
-func updateDatabaseLastWeek(world, typeid int, ranks []rankItem, updateTime time.Time) error {
-	realTime := alignTime(updateTime)
-	return db.Update(func(tx *bolt.Tx) error {
-		br, err := tx.CreateBucketIfNotExists([]byte("recent-" + strconv.Itoa(world) + "-" + strconv.Itoa(typeid)))
-		if err != nil {
-			return err
-		}
-
-		bm, err := tx.CreateBucketIfNotExists([]byte("maxrecord-" + strconv.Itoa(world) + "-" + strconv.Itoa(typeid)))
-		if err != nil {
-			return err
-		}
-
-		bmeta, err := tx.CreateBucketIfNotExists([]byte("metadata-" + strconv.Itoa(world) + "-" + strconv.Itoa(typeid)))
 		if err != nil {
-			return err
+			report.Failures++
+			report.Records = len(ranks)
+			return ranks, report, fmt.Errorf("rankidx=%d: %v", idx, err)
 		}
-		for _, rank := range ranks {
-			dur := []rune(rank.Duration)
-			fl := []rune(rank.FloorStr)
-			idx := 0
-			for _, d := range dur {
-				if unicode.IsNumber(d) {
-					idx++
-				} else {
-					break
-				}
-			}
-
-			idx2 := 0
-			for _, f := range fl {
-				if unicode.IsNumber(f) {
-					idx2++
-				} else {
-					break
-				}
-			}
-
-			var err error
-			if rank.Minute, err = strconv.Atoi(string(dur[:idx])); err != nil {
-				return err
-			}
-			if rank.Second, err = strconv.Atoi(string(dur[idx+2 : len(dur)-1])); err != nil {
-				return err
-			}
-			if rank.Floor, err = strconv.Atoi(string(fl[:idx2])); err != nil {
-				return err
-			}
-			rank.CheckedTimeUnix = realTime.Unix()
-
-			buf, err := json.Marshal(rank)
-			if err != nil {
-				return err
-			}
-
-			mbuf := bm.Get([]byte(rank.Name))
-			if mbuf == nil {
-				bm.Put([]byte(strings.ToLower(rank.Name)), buf)
-				br.Put([]byte(strings.ToLower(rank.Name)), buf)
-				continue
-			}
-
-			var mrank rankItem
-			if err := json.Unmarshal(mbuf, &mrank); err != nil {
-				return err
-			}
-
-			rbuf := br.Get([]byte(rank.Name))
-			if rbuf != nil {
-				var rrank rankItem
-				if err := json.Unmarshal(rbuf, &rrank); err != nil {
-					return err
-				}
-
-				rtime := time.Unix(rrank.CheckedTimeUnix, 0)
-				ryear, rweek := rtime.ISOWeek()
-				cyear, cweek := realTime.ISOWeek()
-				if ryear == cyear && rweek == cweek &&
-					rrank.Floor == rank.Floor && rrank.fullsec() == rank.fullsec() {
-					continue
-				}
-			}
-
-			br.Put([]byte(strings.ToLower(rank.Name)), buf)
+		report.Pages++
 
-			if mrank.Floor < rank.Floor || (mrank.Floor == rank.Floor && mrank.fullsec() > rank.fullsec()) {
-				bm.Put([]byte(strings.ToLower(rank.Name)), buf)
-			}
+		if len(resp.List) == 0 {
+			break
 		}
-
-		ntime := updateTime.Unix()
-		buf := make([]byte, 8)
-		binary.BigEndian.PutUint64(buf, *(*uint64)(unsafe.Pointer(&ntime)))
-
-		startbuf := bmeta.Get([]byte("start"))
-		if startbuf == nil {
-			bmeta.Put([]byte("start"), buf)
+		ranks = append(ranks, resp.List...)
+		if resp.NextIdx <= idx {
+			warnLog.Printf("Crawler: nextidx did not advance for %s (rankidx=%d, nextidx=%d)", serverNameOf(world), idx, resp.NextIdx)
+			break
 		}
-		bmeta.Put([]byte("end"), buf)
-		return nil
-	})
+		idx = resp.NextIdx
+	}
+	report.Records = len(ranks)
+	return ranks, report, nil
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "restore" {
+		runRestore(os.Args[2:])
+		return
+	}
+
 	update := flag.Bool("update", false, "Updates database at start if provided")
 	laddr := flag.String("addr", ":4412", "Bind address for HTTP server")
+	dbPath := flag.String("db", "database.db", "Path to the boltDB database file")
 	token = flag.String("token", "", "Telegram bot token for cron job report")
 	clientID = flag.String("clientid", "", "telegram user id to receive reports")
+	notifyLog := flag.String("notifylog", "", "File to append crawl reports to")
+	webhook := flag.String("webhook", "", "URL to POST crawl reports to as {\"text\": ...} JSON")
+	categoriesFlag := flag.String("categories", "2", "Comma-separated Mu Lung Dojo categories(cateType) to crawl and serve, ignored if -config is given")
+	configPath := flag.String("config", "", "JSON config file for worlds, categories, schedules and rate limit(reloaded on SIGHUP)")
+	sourceURL := flag.String("source", defaultSourceURL, "Base URL of the ranking JSON API")
+	fakeSource := flag.Bool("fakesource", false, "Crawl from a bundled fake ranking server instead of the live site")
+	adminToken = flag.String("admintoken", "", "Bearer token for admin endpoints(disabled if empty)")
+	backupDir = flag.String("backupdir", "", "Directory for scheduled database snapshots(disabled if empty)")
+	backupKeep = flag.Int("backupkeep", 7, "Number of scheduled snapshots to keep")
+	shutdownTimeout := flag.Duration("shutdowntimeout", time.Second*30, "Time to wait for running crawlers and in-flight HTTP requests on shutdown")
 	flag.Parse()
 
-	var err error
-	if bot, err = telebot.NewBot(telebot.Settings{
-		Token:  *token,
-		Poller: nil,
-	}); err != nil {
-		errLog.Fatal("telebot.NewBot:", err)
+	var cfg *config
+	if *configPath != "" {
+		var err error
+		if cfg, err = loadConfig(*configPath); err != nil {
+			errLog.Fatal("loadConfig:", err)
+		}
+	} else {
+		typeids, err := parseCategories(*categoriesFlag)
+		if err != nil {
+			errLog.Fatal("parseCategories:", err)
+		}
+		cfg = defaultConfig(typeids)
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = *laddr
 	}
+	applyConfig(cfg)
 
-	if channel, err = bot.ChatByID(*clientID); err != nil {
-		errLog.Fatal("bot.ChatByID:", err)
+	if *fakeSource {
+		fake := fakenexon.NewServer()
+		defer fake.Close()
+		warnLog.Println("Using fake ranking server at", fake.URL)
+		*sourceURL = fake.URL
 	}
+	source = &nexonSource{BaseURL: *sourceURL}
 
-	bot.Send(channel, "무릉도장 검색기가 시작됩니다.")
+	notifier = setupNotifier(*token, *clientID, *notifyLog, *webhook)
+	notifier.Notify("무릉도장 검색기가 시작됩니다.")
 
+	var err error
 	verbLog.Println("Opening boltDB database")
-	if db, err = bolt.Open("database.db", 0600, nil); err != nil {
-		errLog.Fatal("bolt.Open:", err)
+	if store, err = storage.Open(*dbPath); err != nil {
+		errLog.Fatal("storage.Open(run `dojangserver migrate` if the schema is outdated):", err)
 	}
 	verbLog.Println("Successfully opened database")
 
-	verbLog.Println("Starting initial crawler")
+	if bot != nil {
+		verbLog.Println("Starting telegram bot command poller")
+		setupBotCommands(bot, *clientID)
+		go bot.Start()
+	}
 
-	c := cron.New()
-	c.AddFunc("0 0 7 * * *", crawlJob)
-	c.AddFunc("0 0 6 * * Mon", crawlJobLastWeek)
+	verbLog.Println("Starting initial crawler")
 
 	verbLog.Println("Starting cronjob runner")
-	c.Start()
+	startScheduler(cfg)
 
+	if *configPath != "" {
+		go watchReload(*configPath, cfg)
+	}
+
+	srv := &http.Server{Addr: cfg.Addr}
+	stopped := make(chan struct{})
 	go func() {
 		c_ := make(chan os.Signal, 1)
-		signal.Notify(c_, os.Interrupt)
+		signal.Notify(c_, os.Interrupt, syscall.SIGTERM)
 
 		s := <-c_
-		verbLog.Println("Interrupt signal received:", s)
-		verbLog.Println("Closing DB")
-		if err := db.Close(); err != nil {
-			errLog.Fatal("db.Close:", err)
-		}
-		os.Exit(0)
+		verbLog.Println("Shutdown signal received:", s)
+		shutdown(srv, *shutdownTimeout)
+		close(stopped)
 	}()
 
 	if *update {
@@ -483,83 +366,26 @@ func main() {
 		crawlJob()
 	}
 
-	http.HandleFunc("/getrank", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Cache-Control", "no-cache")
+	http.HandleFunc("/getrank", instrument("/getrank", handleGetRank))
+	http.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		var request struct {
-			World int
-			Type  int
-			Name  string
-		}
-		dec := json.NewDecoder(r.Body)
-
-		if err := dec.Decode(&request); err != nil {
-			errLog.Println("HTTP: Request parse failed:", err)
-			return
-		}
-
-		if err := db.View(func(tx *bolt.Tx) error {
-			var response struct {
-				Ok    bool
-				Rank  rankItem
-				MRank rankItem
-				Start int64
-				End   int64
-			}
-			enc := json.NewEncoder(w)
-
-			br := tx.Bucket([]byte("recent-" + strconv.Itoa(request.World) + "-" + strconv.Itoa(request.Type)))
-			if br == nil {
-				enc.Encode(response)
-				return nil
-			}
-
-			bm := tx.Bucket([]byte("maxrecord-" + strconv.Itoa(request.World) + "-" + strconv.Itoa(request.Type)))
-			if bm == nil {
-				enc.Encode(response)
-				return nil
-			}
-
-			bmeta := tx.Bucket([]byte("metadata-" + strconv.Itoa(request.World) + "-" + strconv.Itoa(request.Type)))
-			if bmeta == nil {
-				enc.Encode(response)
-				return nil
-			}
-
-			rank, mrank := br.Get([]byte(strings.ToLower(request.Name))), bm.Get([]byte(strings.ToLower(request.Name)))
-			start, end := bmeta.Get([]byte("start")), bmeta.Get([]byte("end"))
-			if start != nil && end != nil {
-				ustart, uend := binary.BigEndian.Uint64(start), binary.BigEndian.Uint64(end)
-				response.Start, response.End = *(*int64)(unsafe.Pointer(&ustart)), *(*int64)(unsafe.Pointer(&uend))
-			}
-
-			if rank == nil || mrank == nil {
-				if err := enc.Encode(response); err != nil {
-					return err
-				}
-				return nil
-			}
-			response.Ok = true
-
-			if err := json.Unmarshal(rank, &response.Rank); err != nil {
-				return err
-			}
-			if err := json.Unmarshal(mrank, &response.MRank); err != nil {
-				return err
-			}
-
-			if err := enc.Encode(response); err != nil {
-				return err
-			}
-
-			return nil
-		}); err != nil {
-			errLog.Println("HTTP: db.View failed:", err)
-		}
+		w.Write([]byte(api.OpenAPI))
 	})
-
-	verbLog.Println("Starting HTTP server on", *laddr)
-	if err = http.ListenAndServe(*laddr, nil); err != nil {
+	http.HandleFunc(apiPrefix+"characters/", instrument(apiPrefix+"characters", handleAPICharacter))
+	http.HandleFunc(apiPrefix+"worlds", instrument(apiPrefix+"worlds", handleAPIWorlds))
+	http.HandleFunc("/history", instrument("/history", handleHistory))
+	http.HandleFunc("/history.svg", instrument("/history.svg", handleHistoryChart))
+	http.HandleFunc("/compare", instrument("/compare", handleCompare))
+	http.HandleFunc("/leaderboard", instrument("/leaderboard", handleLeaderboard))
+	http.HandleFunc("/search", instrument("/search", handleSearch))
+	http.HandleFunc("/stats/jobs", instrument("/stats/jobs", handleJobStats))
+	http.HandleFunc("/status", handleStatus)
+	http.HandleFunc("/metrics", handleMetrics)
+	http.HandleFunc("/admin/backup", handleBackup)
+
+	verbLog.Println("Starting HTTP server on", cfg.Addr)
+	if err = srv.ListenAndServe(); err != http.ErrServerClosed {
 		log.Fatal("http.ListenAndServe:", err)
 	}
+	<-stopped
 }
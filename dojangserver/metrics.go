@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// latencyBuckets 는 지연 시간 히스토그램의 구간 상한(초)입니다.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	counts []uint64 // latencyBuckets 각 구간 이하의 누적 개수
+	count  uint64
+	sum    float64
+}
+
+func (h *histogram) observe(v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(latencyBuckets))
+	}
+	for i, le := range latencyBuckets {
+		if v <= le {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+type crawlKey struct {
+	World    int
+	Type     int
+	LastWeek bool
+}
+
+// crawlMetrics 는 한 서버, 카테고리의 크롤링 지표입니다. 누적 값은 서버 시작 이후의 합계입니다.
+type crawlMetrics struct {
+	World           int
+	Name            string
+	Type            int
+	LastWeek        bool
+	LastAttempt     int64   // 마지막 시도 시각(유닉스 시각)
+	LastSuccess     int64   // 마지막으로 모든 페이지를 받아 DB 갱신까지 성공한 시각(유닉스 시각)
+	DurationSeconds float64 // 마지막 수집에 걸린 시간
+	Records         int     // 마지막 수집에서 받은 기록 수
+	ParseFailures   int     // 누적 파싱 실패 수
+	HTTPErrors      int     // 누적 HTTP, 응답 해석 오류 수 (재시도 포함)
+	Failures        int     // 누적 실패한 수집 수. 받은 기록만 일부 저장한 경우도 포함합니다.
+}
+
+var metrics = struct {
+	sync.Mutex
+	crawls      map[crawlKey]*crawlMetrics
+	storeUpdate histogram
+	requests    map[string]*histogram
+}{
+	crawls:   map[crawlKey]*crawlMetrics{},
+	requests: map[string]*histogram{},
+}
+
+func crawlMetricsFor(k crawlKey) *crawlMetrics {
+	m, ok := metrics.crawls[k]
+	if !ok {
+		m = &crawlMetrics{World: k.World, Name: serverNameOf(k.World), Type: k.Type, LastWeek: k.LastWeek}
+		metrics.crawls[k] = m
+	}
+	return m
+}
+
+// observeCrawl 함수는 한 서버의 수집 결과를 기록합니다.
+func observeCrawl(report crawlReport, started time.Time) {
+	metrics.Lock()
+	defer metrics.Unlock()
+	m := crawlMetricsFor(crawlKey{report.World, report.Type, report.LastWeek})
+	m.LastAttempt = started.Unix()
+	m.DurationSeconds = time.Since(started).Seconds()
+	m.Records = report.Records
+	m.HTTPErrors += report.Retries + report.Failures
+}
+
+// observeUpdate 함수는 한 서버의 DB 갱신에 걸린 시간과 파싱 실패 수를 기록합니다.
+func observeUpdate(world, typeid int, lastWeek bool, skipped int, took time.Duration) {
+	metrics.Lock()
+	defer metrics.Unlock()
+	m := crawlMetricsFor(crawlKey{world, typeid, lastWeek})
+	m.ParseFailures += skipped
+	metrics.storeUpdate.observe(took.Seconds())
+}
+
+// observeResult 함수는 한 서버의 수집 결과를 기록합니다. err는 수집 또는 DB 갱신 오류이며,
+// 수집이 중간에 실패했다면 받은 기록을 저장했더라도 실패로 기록해야 합니다.
+func observeResult(world, typeid int, lastWeek bool, err error) {
+	metrics.Lock()
+	defer metrics.Unlock()
+	m := crawlMetricsFor(crawlKey{world, typeid, lastWeek})
+	if err != nil {
+		m.Failures++
+		return
+	}
+	m.LastSuccess = time.Now().Unix()
+}
+
+// instrument 함수는 핸들러의 응답 시간을 path별로 기록합니다.
+func instrument(path string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		h(w, r)
+		took := time.Since(start).Seconds()
+
+		metrics.Lock()
+		hist, ok := metrics.requests[path]
+		if !ok {
+			hist = &histogram{}
+			metrics.requests[path] = hist
+		}
+		hist.observe(took)
+		metrics.Unlock()
+	}
+}
+
+func sortedCrawlMetrics() []crawlMetrics {
+	ret := make([]crawlMetrics, 0, len(metrics.crawls))
+	for _, m := range metrics.crawls {
+		ret = append(ret, *m)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i], ret[j]
+		if a.LastWeek != b.LastWeek {
+			return !a.LastWeek
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.World < b.World
+	})
+	return ret
+}
+
+func crawlerRunningSince(lock sync.Locker, t *int64) int64 {
+	lock.Lock()
+	defer lock.Unlock()
+	return *t
+}
+
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+
+	var response struct {
+		CrawlerRunningSince         int64 // 실행 중이 아니면 0
+		LastWeekCrawlerRunningSince int64
+		Crawls                      []crawlMetrics
+	}
+	response.CrawlerRunningSince = crawlerRunningSince(&lastCrawlTimeLock, &lastCrawlTime)
+	response.LastWeekCrawlerRunningSince = crawlerRunningSince(&lastCrawlTimeLockLastWeek, &lastCrawlTimeLastWeek)
+
+	metrics.Lock()
+	response.Crawls = sortedCrawlMetrics()
+	metrics.Unlock()
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
+
+func writeHistogram(w http.ResponseWriter, name, labels string, h *histogram) {
+	sep := ""
+	if labels != "" {
+		sep = ","
+	}
+	for i, le := range latencyBuckets {
+		var n uint64
+		if h.counts != nil {
+			n = h.counts[i]
+		}
+		fmt.Fprintf(w, "%s_bucket{%s%sle=\"%s\"} %d\n", name, labels, sep, strconv.FormatFloat(le, 'g', -1, 64), n)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s%sle=\"+Inf\"} %d\n", name, labels, sep, h.count)
+	if labels != "" {
+		labels = "{" + labels + "}"
+	}
+	fmt.Fprintf(w, "%s_sum%s %g\n", name, labels, h.sum)
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels, h.count)
+}
+
+// handleMetrics 함수는 Prometheus 텍스트 형식으로 지표를 내보냅니다.
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	thisWeek := crawlerRunningSince(&lastCrawlTimeLock, &lastCrawlTime)
+	lastWeek := crawlerRunningSince(&lastCrawlTimeLockLastWeek, &lastCrawlTimeLastWeek)
+
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	fmt.Fprintln(w, "# HELP dojang_crawler_running_since_seconds Start time of the running crawler, 0 if idle.")
+	fmt.Fprintln(w, "# TYPE dojang_crawler_running_since_seconds gauge")
+	fmt.Fprintf(w, "dojang_crawler_running_since_seconds{week=\"this\"} %d\n", thisWeek)
+	fmt.Fprintf(w, "dojang_crawler_running_since_seconds{week=\"last\"} %d\n", lastWeek)
+
+	crawls := sortedCrawlMetrics()
+	gauges := []struct {
+		name, help, typ string
+		value           func(m crawlMetrics) string
+	}{
+		{"dojang_crawl_last_success_timestamp_seconds", "Last time the world was crawled completely and stored successfully.", "gauge",
+			func(m crawlMetrics) string { return strconv.FormatInt(m.LastSuccess, 10) }},
+		{"dojang_crawl_duration_seconds", "Duration of the last crawl of the world.", "gauge",
+			func(m crawlMetrics) string { return strconv.FormatFloat(m.DurationSeconds, 'g', -1, 64) }},
+		{"dojang_crawl_records", "Records fetched by the last crawl of the world.", "gauge",
+			func(m crawlMetrics) string { return strconv.Itoa(m.Records) }},
+		{"dojang_crawl_parse_failures_total", "Records skipped because floor or duration could not be parsed.", "counter",
+			func(m crawlMetrics) string { return strconv.Itoa(m.ParseFailures) }},
+		{"dojang_crawl_http_errors_total", "Ranking page requests that failed, including retried ones.", "counter",
+			func(m crawlMetrics) string { return strconv.Itoa(m.HTTPErrors) }},
+		{"dojang_crawl_failures_total", "Crawls that failed, including ones that stored partial results.", "counter",
+			func(m crawlMetrics) string { return strconv.Itoa(m.Failures) }},
+	}
+	for _, g := range gauges {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", g.name, g.help, g.name, g.typ)
+		for _, m := range crawls {
+			week := "this"
+			if m.LastWeek {
+				week = "last"
+			}
+			fmt.Fprintf(w, "%s{world=\"%d\",type=\"%d\",week=\"%s\"} %s\n", g.name, m.World, m.Type, week, g.value(m))
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP dojang_store_update_seconds Duration of bolt update transactions for crawl results.")
+	fmt.Fprintln(w, "# TYPE dojang_store_update_seconds histogram")
+	writeHistogram(w, "dojang_store_update_seconds", "", &metrics.storeUpdate)
+
+	paths := make([]string, 0, len(metrics.requests))
+	for path := range metrics.requests {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fmt.Fprintln(w, "# HELP dojang_http_request_seconds Latency of HTTP API requests.")
+	fmt.Fprintln(w, "# TYPE dojang_http_request_seconds histogram")
+	for _, path := range paths {
+		writeHistogram(w, "dojang_http_request_seconds", fmt.Sprintf("path=%q", path), metrics.requests[path])
+	}
+}
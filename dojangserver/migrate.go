@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+// runMigrate 함수는 migrate 하위 명령을 실행합니다. 서버가 실행 중이지 않을 때 사용해야 합니다.
+func runMigrate(args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	path := fs.String("db", "database.db", "Path to the boltDB database file to migrate")
+	dryRun := fs.Bool("dryrun", false, "Report what would change without writing anything")
+	fs.Parse(args)
+
+	verbLog.Println("Migrating", *path, "to schema version", storage.SchemaVersion)
+	report, err := storage.Migrate(*path, *dryRun)
+	if err != nil {
+		errLog.Fatal("storage.Migrate:", err)
+	}
+	if *dryRun {
+		verbLog.Println("Dry run, nothing written:", report)
+		return
+	}
+	verbLog.Println("Migration finished:", report)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tucnak/telebot"
+)
+
+// Notifier 는 크롤링 보고 등 운영 메시지를 받는 곳입니다.
+type Notifier interface {
+	Notify(msg string) error
+}
+
+var notifier Notifier = nopNotifier{}
+
+// nopNotifier 는 메시지를 버립니다.
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(msg string) error {
+	return nil
+}
+
+// telegramNotifier 는 텔레그램 채팅으로 메시지를 보냅니다.
+type telegramNotifier struct {
+	bot  *telebot.Bot
+	chat *telebot.Chat
+}
+
+func (n *telegramNotifier) Notify(msg string) error {
+	_, err := n.bot.Send(n.chat, msg)
+	return err
+}
+
+// logFileNotifier 는 메시지를 시각과 함께 파일에 덧붙입니다.
+type logFileNotifier struct {
+	l *log.Logger
+}
+
+func newLogFileNotifier(path string) (*logFileNotifier, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &logFileNotifier{l: log.New(f, "", log.Ldate|log.Ltime)}, nil
+}
+
+func (n *logFileNotifier) Notify(msg string) error {
+	return n.l.Output(2, msg)
+}
+
+// webhookNotifier 는 {"text": 메시지} 형식의 JSON을 URL로 POST합니다.
+// 슬랙, 디스코드(/slack 호환 주소) 등 대부분의 수신 웹훅이 이 형식을 받습니다.
+type webhookNotifier struct {
+	URL    string
+	client http.Client
+}
+
+func newWebhookNotifier(url string) *webhookNotifier {
+	return &webhookNotifier{URL: url, client: http.Client{Timeout: time.Second * 10}}
+}
+
+func (n *webhookNotifier) Notify(msg string) error {
+	var b bytes.Buffer
+	json.NewEncoder(&b).Encode(struct {
+		Text string `json:"text"`
+	}{msg})
+	resp, err := n.client.Post(n.URL, "application/json", &b)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook: unexpected HTTP status %s", resp.Status)
+	}
+	return nil
+}
+
+// multiNotifier 는 모든 곳에 차례로 메시지를 보냅니다. 한 곳이 실패해도 나머지에는 보내며,
+// 실패는 로그로 남기고 첫 번째 오류를 반환합니다. 보내는 동안에는 잠금을 쥐지 않으므로
+// 느린 곳이 있어도 다른 고루틴(예: 다른 서버의 크롤러)의 알림은 기다리지 않습니다.
+type multiNotifier struct {
+	mu        sync.Mutex
+	notifiers []Notifier
+}
+
+func (n *multiNotifier) Notify(msg string) error {
+	n.mu.Lock()
+	sinks := append([]Notifier(nil), n.notifiers...)
+	n.mu.Unlock()
+
+	var ret error
+	for _, sink := range sinks {
+		if err := sink.Notify(msg); err != nil {
+			warnLog.Printf("Notifier: %T failed: %v", sink, err)
+			if ret == nil {
+				ret = err
+			}
+		}
+	}
+	return ret
+}
+
+// setupNotifier 함수는 플래그에 따라 Notifier를 구성합니다. 설정된 곳이 없다면 메시지를 버립니다.
+// 텔레그램 봇을 만들 수 없더라도 서버는 계속 실행됩니다.
+func setupNotifier(token, clientID, logPath, webhookURL string) Notifier {
+	var notifiers []Notifier
+
+	if token != "" {
+		var err error
+		if bot, err = telebot.NewBot(telebot.Settings{
+			Token:  token,
+			Poller: &telebot.LongPoller{Timeout: 10 * time.Second},
+		}); err != nil {
+			errLog.Println("telebot.NewBot:", err)
+			bot = nil
+		} else if channel, err = bot.ChatByID(clientID); err != nil {
+			errLog.Println("bot.ChatByID:", err)
+		} else {
+			notifiers = append(notifiers, &telegramNotifier{bot: bot, chat: channel})
+		}
+	}
+
+	if logPath != "" {
+		n, err := newLogFileNotifier(logPath)
+		if err != nil {
+			errLog.Println("newLogFileNotifier:", err)
+		} else {
+			notifiers = append(notifiers, n)
+		}
+	}
+
+	if webhookURL != "" {
+		notifiers = append(notifiers, newWebhookNotifier(webhookURL))
+	}
+
+	if len(notifiers) == 0 {
+		warnLog.Println("No notifier configured, reports are only logged")
+		return nopNotifier{}
+	}
+	return &multiNotifier{notifiers: notifiers}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// blockingNotifier 는 "slow" 메시지를 release가 닫힐 때까지 붙잡고 있습니다.
+type blockingNotifier struct {
+	release chan struct{}
+}
+
+func (n *blockingNotifier) Notify(msg string) error {
+	if msg == "slow" {
+		<-n.release
+	}
+	return nil
+}
+
+func TestMultiNotifierSlowSink(t *testing.T) {
+	sink := &blockingNotifier{release: make(chan struct{})}
+	defer close(sink.release)
+	n := &multiNotifier{notifiers: []Notifier{sink}}
+
+	go n.Notify("slow")
+	time.Sleep(time.Millisecond * 10)
+
+	done := make(chan struct{})
+	go func() {
+		n.Notify("fast")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Notify waited for another goroutine's slow sink")
+	}
+}
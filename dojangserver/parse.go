@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+// parseNumber 함수는 ASCII 숫자로만 이루어진 음이 아닌 정수를 파싱합니다.
+// 부호나 다른 문자 체계의 숫자는 허용하지 않으며, 범위를 넘으면 strconv의 오류를 반환합니다.
+func parseNumber(s string) (int, error) {
+	if s == "" {
+		return 0, fmt.Errorf("missing number")
+	}
+	for i := 0; i < len(s); i++ {
+		if !isDigit(rune(s[i])) {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+// parseFloor 함수는 "61층", " 61 층", "61" 형식의 층수를 파싱합니다.
+func parseFloor(s string) (int, error) {
+	t := strings.TrimSpace(s)
+	t = strings.TrimSpace(strings.TrimSuffix(t, "층"))
+	floor, err := parseNumber(t)
+	if err != nil {
+		return 0, fmt.Errorf("invalid floor %q: %v", s, err)
+	}
+	return floor, nil
+}
+
+// parseDuration 함수는 소요 시간을 분, 초로 파싱합니다. 시간 단위는 분에 합산됩니다.
+// "13분 2초", "13분2초", "59초", "13분", "1시간 2분 3초", "13:02" 형식을 지원하며, 어느 형식이든 초는 60 미만이어야 합니다.
+func parseDuration(s string) (minute, second int, err error) {
+	t := strings.TrimSpace(s)
+	if t == "" {
+		return 0, 0, fmt.Errorf("invalid duration %q: empty", s)
+	}
+
+	if idx := strings.IndexByte(t, ':'); idx >= 0 {
+		if minute, err = parseNumber(strings.TrimSpace(t[:idx])); err != nil {
+			return 0, 0, fmt.Errorf("invalid duration %q: minute: %v", s, err)
+		}
+		if second, err = parseNumber(strings.TrimSpace(t[idx+1:])); err != nil {
+			return 0, 0, fmt.Errorf("invalid duration %q: second: %v", s, err)
+		}
+		if second >= 60 {
+			return 0, 0, fmt.Errorf("invalid duration %q: second %d out of range", s, second)
+		}
+		return minute, second, nil
+	}
+
+	rs := []rune(t)
+	seen := map[string]bool{}
+	for i := 0; i < len(rs); {
+		if unicode.IsSpace(rs[i]) {
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(rs) && isDigit(rs[j]) {
+			j++
+		}
+		n, err := parseNumber(string(rs[i:j]))
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid duration %q: %v", s, err)
+		}
+
+		for j < len(rs) && unicode.IsSpace(rs[j]) {
+			j++
+		}
+		k := j
+		for k < len(rs) && !isDigit(rs[k]) && !unicode.IsSpace(rs[k]) {
+			k++
+		}
+		unit := string(rs[j:k])
+		if seen[unit] {
+			return 0, 0, fmt.Errorf("invalid duration %q: duplicate unit %q", s, unit)
+		}
+		seen[unit] = true
+
+		switch unit {
+		case "시간":
+			minute += n * 60
+		case "분":
+			minute += n
+		case "초":
+			if n >= 60 {
+				return 0, 0, fmt.Errorf("invalid duration %q: second %d out of range", s, n)
+			}
+			second = n
+		default:
+			return 0, 0, fmt.Errorf("invalid duration %q: unknown unit %q", s, unit)
+		}
+		i = k
+	}
+	return minute, second, nil
+}
+
+// parseRecord 함수는 랭킹 항목의 층수와 소요 시간 문자열을 파싱하여 해당 필드를 채웁니다.
+func parseRecord(rank *storage.Record) error {
+	var err error
+	if rank.Floor, err = parseFloor(rank.FloorStr); err != nil {
+		return err
+	}
+	if rank.Minute, rank.Second, err = parseDuration(rank.Duration); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseRecords 함수는 각 기록을 파싱합니다. 파싱할 수 없는 기록은 건너뛰고 그 오류 목록을 함께 반환합니다.
+func parseRecords(world int, ranks []storage.Record) (records []storage.Record, skipped []error) {
+	records = make([]storage.Record, 0, len(ranks))
+	for _, rank := range ranks {
+		if err := parseRecord(&rank); err != nil {
+			warnLog.Printf("Crawler: Skipping %s in %s: %v", rank.Name, serverNameOf(world), err)
+			skipped = append(skipped, fmt.Errorf("%s: %v", rank.Name, err))
+			continue
+		}
+		records = append(records, rank)
+	}
+	return records, skipped
+}
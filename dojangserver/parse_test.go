@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in             string
+		minute, second int
+		err            string
+	}{
+		{in: "13분 2초", minute: 13, second: 2},
+		{in: "13분2초", minute: 13, second: 2},
+		{in: "59초", second: 59},
+		{in: "13분", minute: 13},
+		{in: "1시간 2분 3초", minute: 62, second: 3},
+		{in: "13:02", minute: 13, second: 2},
+		{in: "  13 분   2 초 ", minute: 13, second: 2},
+		{in: " 13 : 02 ", minute: 13, second: 2},
+
+		{in: "", err: `invalid duration "": empty`},
+		{in: "   ", err: `invalid duration "   ": empty`},
+		{in: "13분 75초", err: `invalid duration "13분 75초": second 75 out of range`},
+		{in: "13:75", err: `invalid duration "13:75": second 75 out of range`},
+		{in: "13:", err: `invalid duration "13:": second: missing number`},
+		{in: "-1:02", err: `invalid duration "-1:02": minute: invalid number "-1"`},
+		{in: "분 2초", err: `invalid duration "분 2초": missing number`},
+		{in: "13", err: `invalid duration "13": unknown unit ""`},
+		{in: "13일", err: `invalid duration "13일": unknown unit "일"`},
+		{in: "13분 2분", err: `invalid duration "13분 2분": duplicate unit "분"`},
+		{in: "١٣분", err: `invalid duration "١٣분": missing number`},
+		{in: "99999999999999999999분", err: `invalid duration "99999999999999999999분": strconv.Atoi: parsing "99999999999999999999": value out of range`},
+	}
+	for _, tt := range tests {
+		minute, second, err := parseDuration(tt.in)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parseDuration(%q) error = %v, want %s", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseDuration(%q): %v", tt.in, err)
+			continue
+		}
+		if minute != tt.minute || second != tt.second {
+			t.Errorf("parseDuration(%q) = %d, %d, want %d, %d", tt.in, minute, second, tt.minute, tt.second)
+		}
+	}
+}
+
+func TestParseFloor(t *testing.T) {
+	tests := []struct {
+		in    string
+		floor int
+		err   string
+	}{
+		{in: "61층", floor: 61},
+		{in: " 61 층", floor: 61},
+		{in: "61", floor: 61},
+		{in: "", err: `invalid floor "": missing number`},
+		{in: "층", err: `invalid floor "층": missing number`},
+		{in: "-1층", err: `invalid floor "-1층": invalid number "-1"`},
+		{in: "육십층", err: `invalid floor "육십층": invalid number "육십"`},
+	}
+	for _, tt := range tests {
+		floor, err := parseFloor(tt.in)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("parseFloor(%q) error = %v, want %s", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || floor != tt.floor {
+			t.Errorf("parseFloor(%q) = %d, %v, want %d", tt.in, floor, err, tt.floor)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+func formerNames(names []storage.FormerName) []api.FormerName {
+	ret := make([]api.FormerName, 0, len(names))
+	for _, n := range names {
+		ret = append(ret, api.FormerName{Name: n.Name, World: n.World, WorldName: serverNameOf(n.World), Until: n.Until})
+	}
+	return ret
+}
+
+// readRank 함수는 캐릭터의 기록과 순위 정보를 읽습니다. 기록이 없으면 Ok가 거짓인 응답을 반환합니다.
+func readRank(world, typeid int, name string) (api.RankResponse, error) {
+	response := api.RankResponse{World: world, WorldName: serverNameOf(world), Type: typeid}
+
+	meta, err := store.Meta(world, typeid)
+	if err != nil {
+		return response, err
+	}
+	response.Start, response.End = meta.Start, meta.End
+
+	c, err := store.GetCharacter(world, typeid, name)
+	if err != nil || c == nil {
+		return response, err
+	}
+	response.Ok = true
+	response.ID, response.Rank, response.MRank = c.ID, apiRecord(c.Recent), apiRecord(c.Max)
+	response.FormerNames = formerNames(c.FormerNames)
+	if response.Weekly, err = weeklyContext(world, typeid, c.Recent); err != nil {
+		return response, err
+	}
+	if response.AllTime, err = allTimeContext(world, typeid, c.Max); err != nil {
+		return response, err
+	}
+	return response, nil
+}
+
+func handleGetRank(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	var request api.CharacterRequest
+	dec := json.NewDecoder(r.Body)
+
+	if err := dec.Decode(&request); err != nil {
+		errLog.Println("HTTP: Request parse failed:", err)
+		return
+	}
+
+	response, err := readRank(request.World, request.Type, request.Name)
+	if err != nil {
+		errLog.Println("HTTP: readRank failed:", err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+type recordKey struct {
+	Floor int
+	Sec   int
+}
+
+func (a recordKey) better(b recordKey) bool {
+	return a.Floor > b.Floor || (a.Floor == b.Floor && a.Sec < b.Sec)
+}
+
+// floorDist 는 한 서버, 카테고리, 주의 기록 분포입니다.
+type floorDist struct {
+	records []recordKey // 좋은 기록부터 정렬됨
+	floors  []int       // 도달한 층 목록, 오름차순
+	counts  map[int]int // 층별 인원
+}
+
+type distKey struct {
+	World int
+	Type  int
+	Week  string // 비어 있으면 전체 기간 최고 기록
+}
+
+// distGenKey 는 분포 캐시의 세대를 구분하는 서버, 카테고리입니다.
+type distGenKey struct {
+	World int
+	Type  int
+}
+
+// distCache 는 크롤링이 끝날 때마다 해당 서버, 카테고리 항목이 지워지는 분포 캐시입니다.
+// gen은 서버, 카테고리별로 캐시를 지운 횟수이며, 계산하는 동안 바뀌었다면 결과를 캐시하지 않습니다.
+var distCache = struct {
+	sync.Mutex
+	m   map[distKey]*floorDist
+	gen map[distGenKey]uint64
+}{m: map[distKey]*floorDist{}, gen: map[distGenKey]uint64{}}
+
+// invalidateDist 함수는 한 서버, 카테고리의 캐시된 분포를 모두 지웁니다. DB를 갱신한 뒤 호출해야 합니다.
+func invalidateDist(world, typeid int) {
+	distCache.Lock()
+	defer distCache.Unlock()
+	distCache.gen[distGenKey{world, typeid}]++
+	for k := range distCache.m {
+		if k.World == world && k.Type == typeid {
+			delete(distCache.m, k)
+		}
+	}
+}
+
+// getDist 함수는 캐시된 분포를 반환합니다. 캐시에 없으면 계산해서 캐시합니다.
+func getDist(world, typeid int, week string) (*floorDist, error) {
+	key := distKey{world, typeid, week}
+	distCache.Lock()
+	d, ok := distCache.m[key]
+	gen := distCache.gen[distGenKey{world, typeid}]
+	distCache.Unlock()
+	if ok {
+		return d, nil
+	}
+
+	d, err := distLoader(world, typeid, week)
+	if err != nil {
+		return nil, err
+	}
+
+	// 계산하는 동안 크롤링이 끝나 캐시가 지워졌다면 갱신 전 데이터일 수 있으므로 캐시하지 않습니다.
+	// 그대로 두면 그 서버의 다음 크롤링까지 오래된 분포가 남습니다.
+	distCache.Lock()
+	if distCache.gen[distGenKey{world, typeid}] == gen {
+		distCache.m[key] = d
+	}
+	distCache.Unlock()
+	return d, nil
+}
+
+// distLoader 는 DB에서 분포를 계산하는 함수입니다. 테스트에서 바꿔 끼웁니다.
+var distLoader = loadDist
+
+// loadDist 함수는 DB에서 한 서버, 카테고리, 주의 기록 분포를 계산합니다. week가 비어 있으면 최고 기록의 분포입니다.
+func loadDist(world, typeid int, week string) (*floorDist, error) {
+	d := &floorDist{counts: map[int]int{}}
+	collect := func(r storage.Record) error {
+		d.records = append(d.records, recordKey{r.Floor, r.FullSec()})
+		d.counts[r.Floor]++
+		return nil
+	}
+	var err error
+	if week == "" {
+		err = store.ForEachMax(world, typeid, collect)
+	} else {
+		err = store.ForEachWeek(world, typeid, week, collect)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(d.records, func(i, j int) bool {
+		return d.records[i].better(d.records[j])
+	})
+	for floor := range d.counts {
+		d.floors = append(d.floors, floor)
+	}
+	sort.Ints(d.floors)
+	return d, nil
+}
+
+func (d *floorDist) context(r storage.Record) api.RankContext {
+	me := recordKey{r.Floor, r.FullSec()}
+	n := len(d.records)
+	better := sort.Search(n, func(i int) bool { return !d.records[i].better(me) })
+	notWorse := sort.Search(n, func(i int) bool { return me.better(d.records[i]) })
+
+	ret := api.RankContext{Rank: better + 1, Total: n, SameFloor: d.counts[r.Floor]}
+	if n > 0 {
+		ret.Percentile = float64(n-notWorse) / float64(n) * 100
+		ret.TopPercent = float64(better+1) / float64(n) * 100
+	}
+	if i := sort.SearchInts(d.floors, r.Floor+1); i < len(d.floors) {
+		ret.NextFloor = d.floors[i]
+		ret.FloorGap = d.floors[i] - r.Floor
+	}
+	return ret
+}
+
+// weeklyContext 함수는 기록이 속한 주의 기록들과 비교한 위치를 반환합니다.
+func weeklyContext(world, typeid int, r storage.Record) (*api.RankContext, error) {
+	week := storage.WeekKey(time.Unix(r.CheckedTimeUnix, 0))
+	d, err := getDist(world, typeid, week)
+	if err != nil {
+		return nil, err
+	}
+	ctx := d.context(r)
+	ctx.Week = week
+	return &ctx, nil
+}
+
+// allTimeContext 함수는 모든 캐릭터의 최고 기록과 비교한 위치를 반환합니다.
+func allTimeContext(world, typeid int, r storage.Record) (*api.RankContext, error) {
+	d, err := getDist(world, typeid, "")
+	if err != nil {
+		return nil, err
+	}
+	ctx := d.context(r)
+	return &ctx, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestGetDistInvalidatedWhileLoading(t *testing.T) {
+	oldLoader := distLoader
+	t.Cleanup(func() {
+		distLoader = oldLoader
+		invalidateDist(1, 2)
+	})
+
+	loads := 0
+	distLoader = func(world, typeid int, week string) (*floorDist, error) {
+		loads++
+		if loads == 1 {
+			// 계산하는 동안 크롤링이 끝났습니다.
+			invalidateDist(world, typeid)
+		}
+		return &floorDist{counts: map[int]int{}}, nil
+	}
+
+	first, err := getDist(1, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := getDist(1, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second || loads != 2 {
+		t.Fatalf("distribution computed before invalidation was cached (loads = %d)", loads)
+	}
+	if third, _ := getDist(1, 2, ""); third != second || loads != 2 {
+		t.Errorf("distribution was not cached after an undisturbed load (loads = %d)", loads)
+	}
+
+	invalidateDist(1, 2)
+	getDist(1, 2, "")
+	if loads != 3 {
+		t.Errorf("loads after invalidateDist = %d, want 3", loads)
+	}
+}
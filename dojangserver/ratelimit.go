@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket 은 여러 고루틴이 함께 쓰는 토큰 버킷 요청 제한기입니다.
+// 토큰은 초당 rate개씩 채워지며 최대 burst개까지 쌓입니다.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64 // 기다리는 요청이 미리 가져간 만큼 음수가 될 수 있습니다.
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// 호출하는 쪽에서 mu를 잡고 있어야 합니다.
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// SetRate 함수는 초당 토큰 수를 바꿉니다. 이미 기다리고 있는 요청에는 적용되지 않습니다.
+func (b *tokenBucket) SetRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	b.rate = rate
+}
+
+// Wait 함수는 토큰을 하나 얻을 때까지 기다립니다. 먼저 호출한 순서대로 토큰을 얻으며,
+// ctx가 이미 취소되었다면 토큰을 주지 않으며, 기다리는 도중 취소되면 토큰을 돌려놓습니다. 두 경우 모두 ctx.Err()를 반환합니다.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.refill(time.Now())
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// limiter 는 모든 서버의 랭킹 페이지 요청이 함께 쓰는 제한기입니다. 속도는 설정의 rate_limit을 따릅니다.
+var limiter = newTokenBucket(defaultRateLimit, 1)
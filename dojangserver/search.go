@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+const defaultSearchSize = 20
+const maxSearchSize = 100
+
+// searchWorld 함수는 한 서버의 최고 기록에서 닉네임 후보를 찾습니다.
+// 접두사 일치를 먼저 찾고, 없다면 자모 단위 편집 거리로 유사한 닉네임을 찾습니다.
+func searchWorld(world, typeid int, query string) ([]api.SearchCandidate, error) {
+	var ret []api.SearchCandidate
+	add := func(rank storage.Record, distance int) {
+		ret = append(ret, api.SearchCandidate{
+			World:     world,
+			WorldName: serverNameOf(world),
+			Name:      rank.Name,
+			Job:       rank.Job,
+			DetailJob: rank.DetailJob,
+			Floor:     rank.Floor,
+			Duration:  rank.Duration,
+			Distance:  distance,
+		})
+	}
+
+	if err := store.SeekMax(world, typeid, query, func(rank storage.Record) error {
+		add(rank, 0)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if len(ret) > 0 {
+		return ret, nil
+	}
+
+	// 입력 중인 음절(예: "홍길" 대신 "홍기")도 찾을 수 있도록 자모 단위로 비교합니다.
+	qj := decomposeHangul(query)
+	maxDistance := len(qj) / 4
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+	if err := store.ForEachMax(world, typeid, func(rank storage.Record) error {
+		kj := decomposeHangul(strings.ToLower(rank.Name))
+		if hasRunePrefix(kj, qj) {
+			add(rank, 0)
+		} else if d := editDistance(kj, qj); d <= maxDistance {
+			add(rank, d)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func handleSearch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	query := strings.ToLower(strings.TrimSpace(q.Get("q")))
+	if query == "" {
+		http.Error(w, "empty query", http.StatusBadRequest)
+		return
+	}
+
+	typeid := categories()[0]
+	if s := q.Get("type"); s != "" {
+		var err error
+		if typeid, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "invalid type", http.StatusBadRequest)
+			return
+		}
+	}
+
+	n := defaultSearchSize
+	if s := q.Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n <= 0 {
+			http.Error(w, "invalid n", http.StatusBadRequest)
+			return
+		}
+		if n > maxSearchSize {
+			n = maxSearchSize
+		}
+	}
+
+	var response api.SearchResponse
+
+	for _, world := range servers() {
+		candidates, err := searchWorld(world, typeid, query)
+		if err != nil {
+			errLog.Println("HTTP: searchWorld failed:", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		response.Candidates = append(response.Candidates, candidates...)
+	}
+
+	sort.SliceStable(response.Candidates, func(i, j int) bool {
+		a, b := response.Candidates[i], response.Candidates[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		return a.Floor > b.Floor
+	})
+	if len(response.Candidates) > n {
+		response.Candidates = response.Candidates[:n]
+	}
+	response.Ok = len(response.Candidates) > 0
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
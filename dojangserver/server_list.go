@@ -1,20 +1,127 @@
 package main
 
-var serverName = map[int]string{
-	1:  "리부트",
-	12: "리부트2",
-	2:  "오로라",
-	3:  "레드",
-	4:  "이노시스",
-	5:  "유니온",
-	6:  "스카니아",
-	7:  "루나",
-	8:  "제니스",
-	9:  "크로아",
-	10: "베라",
-	11: "엘리시움",
-	50: "아케인",
-	14: "노바",
-}
-
-var serverList = []int{1, 12, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 50, 14}
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultWorlds 는 설정 파일이 없을 때 사용하는 서버 목록입니다. 순서대로 검색 페이지에 표시됩니다.
+var defaultWorlds = []worldConfig{
+	{ID: 1, Name: "리부트"},
+	{ID: 12, Name: "리부트2"},
+	{ID: 2, Name: "오로라"},
+	{ID: 3, Name: "레드"},
+	{ID: 4, Name: "이노시스"},
+	{ID: 5, Name: "유니온"},
+	{ID: 6, Name: "스카니아"},
+	{ID: 7, Name: "루나"},
+	{ID: 8, Name: "제니스"},
+	{ID: 9, Name: "크로아"},
+	{ID: 10, Name: "베라"},
+	{ID: 11, Name: "엘리시움"},
+	{ID: 50, Name: "아케인"},
+	{ID: 14, Name: "노바"},
+}
+
+// defaultCategories 는 랭킹 페이지의 cateType 값과 그 이름입니다.
+// 설정 파일이 없다면 -categories 플래그로 사용할 카테고리를 고릅니다.
+var defaultCategories = []categoryConfig{
+	{ID: 1, Name: "일반"},
+	{ID: 2, Name: "챌린저"},
+	{ID: 3, Name: "기타"},
+}
+
+// 아래 값들은 설정을 다시 읽을 때 바뀌므로 worldsLock을 잡고 접근해야 합니다. 직접 접근하는 대신
+// servers, serverNameOf, categories, categoryNameOf 함수를 사용합니다.
+var worldsLock sync.RWMutex
+var serverName = map[int]string{}   // 비활성화된 서버를 포함한 모든 서버 이름
+var serverList []int                // 수집 및 검색 대상 서버 목록
+var categoryName = map[int]string{} // 비활성화된 카테고리를 포함한 모든 카테고리 이름
+var categoryList []int              // 수집 및 검색 대상 카테고리 목록
+
+// servers 함수는 수집 및 검색 대상 서버 목록을 반환합니다.
+func servers() []int {
+	worldsLock.RLock()
+	defer worldsLock.RUnlock()
+	return serverList
+}
+
+func serverNameOf(world int) string {
+	worldsLock.RLock()
+	defer worldsLock.RUnlock()
+	if name, ok := serverName[world]; ok {
+		return name
+	}
+	return strconv.Itoa(world)
+}
+
+// categories 함수는 수집 및 검색 대상 카테고리 목록을 반환합니다. 첫 번째 카테고리가 기본값입니다.
+func categories() []int {
+	worldsLock.RLock()
+	defer worldsLock.RUnlock()
+	return categoryList
+}
+
+func categoryNameOf(typeid int) string {
+	worldsLock.RLock()
+	defer worldsLock.RUnlock()
+	if name, ok := categoryName[typeid]; ok {
+		return name
+	}
+	return strconv.Itoa(typeid)
+}
+
+// knownCategory 함수는 typeid가 설정에 있는 카테고리인지 반환합니다. 비활성화된 카테고리도 포함합니다.
+func knownCategory(typeid int) bool {
+	worldsLock.RLock()
+	defer worldsLock.RUnlock()
+	_, ok := categoryName[typeid]
+	return ok
+}
+
+// parseCategories 함수는 쉼표로 구분된 cateType 목록을 파싱합니다.
+func parseCategories(s string) ([]int, error) {
+	known := map[int]bool{}
+	for _, c := range defaultCategories {
+		known[c.ID] = true
+	}
+
+	var ret []int
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		typeid, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		if !known[typeid] {
+			return nil, fmt.Errorf("unknown category %d", typeid)
+		}
+		ret = append(ret, typeid)
+	}
+	if len(ret) == 0 {
+		return nil, fmt.Errorf("no category given")
+	}
+	return ret, nil
+}
+
+// parseWorld 함수는 서버 이름(예: 리부트) 또는 번호를 서버 번호로 변환합니다.
+func parseWorld(s string) (int, bool) {
+	worldsLock.RLock()
+	defer worldsLock.RUnlock()
+	for _, world := range serverList {
+		if serverName[world] == s {
+			return world, true
+		}
+	}
+	world, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	_, ok := serverName[world]
+	return world, ok
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// shutdown 함수는 서버를 순서대로 종료합니다. 새 작업이 시작되지 않도록 cron 실행기를 멈추고,
+// 진행 중인 크롤링을 취소한 뒤 DB 갱신이 끝나기를 기다립니다. 이어서 처리 중인 HTTP 요청을
+// 기다린 다음 마지막으로 DB를 닫습니다. 크롤링과 HTTP 요청은 합쳐서 timeout까지만 기다리며,
+// 그때까지 크롤링이 멈추지 않았다면 저장 중인 트랜잭션을 보호하기 위해 DB를 닫지 않습니다.
+func shutdown(srv *http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	verbLog.Println("Stopping cronjob runner")
+	stopScheduler()
+
+	verbLog.Println("Cancelling running crawlers")
+	stopCrawls()
+	crawlsStopped := make(chan struct{})
+	go func() {
+		crawlWG.Wait()
+		close(crawlsStopped)
+	}()
+	select {
+	case <-crawlsStopped:
+	case <-ctx.Done():
+		errLog.Println("Crawler: Running crawlers did not stop within", timeout)
+	}
+
+	if bot != nil {
+		verbLog.Println("Stopping telegram bot command poller")
+		bot.Stop()
+	}
+
+	verbLog.Println("Shutting down HTTP server")
+	if err := srv.Shutdown(ctx); err != nil {
+		errLog.Println("HTTP: Shutdown did not finish in time:", err)
+	}
+
+	select {
+	case <-crawlsStopped:
+	default:
+		errLog.Println("Leaving DB open because a crawler is still running")
+		return
+	}
+	verbLog.Println("Closing DB")
+	if err := store.Close(); err != nil {
+		errLog.Println("store.Close:", err)
+		return
+	}
+	verbLog.Println("Shutdown complete")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+const defaultSourceURL = "http://m.maplestory.nexon.com"
+
+const (
+	thisWeekPath = "/MapleStory/Data/Json/Ranking/DojangThisWeekListJson.aspx"
+	lastWeekPath = "/MapleStory/Data/Json/Ranking/DojangLastWeekListJson.aspx"
+)
+
+// sourceTimeout 은 랭킹 페이지 요청 하나에 허용하는 시간입니다.
+const sourceTimeout = time.Second * 30
+
+// rankSource 는 무릉도장 랭킹 페이지를 제공하는 곳입니다.
+type rankSource interface {
+	// FetchPage 함수는 rankidx부터 시작하는 랭킹 한 페이지를 반환합니다. ctx가 취소되면 요청을 중단합니다.
+	FetchPage(ctx context.Context, world, typeid int, lastWeek bool, rankidx int) (*rankPage, error)
+}
+
+var source rankSource = &nexonSource{BaseURL: defaultSourceURL}
+
+var sourceClient = &http.Client{Timeout: sourceTimeout}
+
+// nexonSource 는 넥슨 모바일 홈페이지의 JSON API(또는 같은 형식의 서버)에서 랭킹을 가져옵니다.
+// Client가 nil이면 sourceTimeout이 설정된 sourceClient를 사용합니다.
+type nexonSource struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func (s *nexonSource) FetchPage(ctx context.Context, world, typeid int, lastWeek bool, rankidx int) (*rankPage, error) {
+	path := thisWeekPath
+	if lastWeek {
+		path = lastWeekPath
+	}
+	u, err := url.Parse(strings.TrimRight(s.BaseURL, "/") + path)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Add("rankidx", strconv.Itoa(rankidx))
+	q.Add("cateType", strconv.Itoa(typeid))
+	q.Add("GameWorldID", strconv.Itoa(world))
+	u.RawQuery = q.Encode()
+	client := s.Client
+	if client == nil {
+		client = sourceClient
+	}
+	return fetchRankPage(ctx, client, u.String())
+}
+
+type rankPage struct {
+	Result  string           `json:"result"`
+	List    []storage.Record `json:"list"`
+	NextIdx int              `json:"nextidx,string"`
+}
+
+// fetchRankPage 함수는 랭킹 한 페이지를 요청합니다. 응답 본문을 해석할 수 없으면 오류를 반환하며,
+// 오류가 없고 List가 비어 있다면 실제로 마지막 페이지를 지난 것입니다.
+func fetchRankPage(ctx context.Context, client *http.Client, u string) (*rankPage, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	r, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	defer io.Copy(ioutil.Discard, r.Body)
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", r.Status)
+	}
+
+	var resp rankPage
+	if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode: %v", err)
+	}
+	return &resp, nil
+}
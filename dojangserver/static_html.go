@@ -13,10 +13,13 @@ const webcontent = `
 	<script src="/json3.js"></script>
 	<script>
 $("document").ready(function() {
-	var params = decodeURI(window.location.search).substring(1).split(":", 2);
+	var params = decodeURI(window.location.search).substring(1).split(":", 3);
 	if(params.length >= 2) {
 		$("#server").val(params[0]);
 		$("#username").val(params[1]);
+		if(params.length >= 3) {
+			$("#type").val(params[2]);
+		}
 		search(false);
 	}
 
@@ -24,12 +27,133 @@ $("document").ready(function() {
 		event.preventDefault();
 		search(true);
 	});
+
+	var d = new Date();
+	d.setDate(d.getDate() - 1);
+	for (var i = 0; i < 8; i++) {
+		var week = isoWeek(d);
+		$("#statsweek").append($("<option>").val(week).text(week + " 주"));
+		d.setDate(d.getDate() - 7);
+	}
+	$("#statsfrm").submit(function(event) {
+		event.preventDefault();
+		loadJobStats();
+	});
+	$("#comparefrm").submit(function(event) {
+		event.preventDefault();
+		loadCompare();
+	});
 });
 
+function isoWeek(date) {
+	var t = new Date(Date.UTC(date.getFullYear(), date.getMonth(), date.getDate()));
+	var day = t.getUTCDay() || 7;
+	t.setUTCDate(t.getUTCDate() + 4 - day);
+	var year = t.getUTCFullYear();
+	var week = Math.ceil(((t - Date.UTC(year, 0, 1)) / 86400000 + 1) / 7);
+	return year + "-W" + (week < 10 ? "0" : "") + week;
+}
+
+function loadJobStats() {
+	$("#stats").text("통계 계산 중...");
+	var world = $("#statsall").is(":checked") ? "" : $("#server").val();
+	$.ajax({
+		type: "GET",
+		url: "/stats/jobs",
+		data: {"world": world, "type": $("#type").val(), "week": $("#statsweek").val()},
+		dataType: "json",
+		success: function(data) {
+			if (!data.Ok || data.Total == 0) {
+				$("#stats").text("해당 기간의 기록이 없습니다.");
+				return false;
+			}
+			$("#stats").html(createJobStats(data));
+		},
+		error: function() {
+			$("#stats").text("통계 조회 중 오류가 발생했습니다.");
+		}
+	});
+}
+
+function createJobStats(data) {
+	var ret = "<table class=\"table is-narrow is-striped\"><thead><tr>" +
+		"<th>세부직업</th><th>인원</th><th>중앙값</th><th>하위 25%%</th><th>상위 25%%</th><th>상위 10%%</th>" +
+		"<th>중앙 소요 시간</th><th>최고 기록</th></tr></thead><tbody>";
+	for (var i = 0; i < data.Classes.length; i++) {
+		var c = data.Classes[i];
+		ret += "<tr><td>" + $("<span>").text(c.DetailJob).html() + "</td><td>" + c.Count + "</td><td>" + c.MedianFloor + "층</td><td>" +
+			c.P25Floor + "층</td><td>" + c.P75Floor + "층</td><td>" + c.P90Floor + "층</td><td>" +
+			Math.floor(c.MedianSec / 60) + "분 " + (c.MedianSec %% 60) + "초</td><td>" +
+			$("<span>").text(c.Best.nick + " " + c.Best.floor + " (" + c.Best.duration + ")").html() + "</td></tr>";
+	}
+	return ret + "</tbody></table>총 " + data.Total + "명";
+}
+
+function loadCompare() {
+	var params = "type=" + encodeURIComponent($("#type").val());
+	var names = $("#comparenames").val().split(",");
+	for (var i = 0; i < names.length; i++) {
+		var name = $.trim(names[i]);
+		if (name == "") {
+			continue;
+		}
+		// 서버를 적지 않은 닉네임은 검색창에서 고른 서버로 봅니다.
+		if (name.indexOf(":") < 0) {
+			name = $("#server").val() + ":" + name;
+		}
+		params += "&c=" + encodeURIComponent(name);
+	}
+	$("#compare").text("비교 중...");
+	$.ajax({
+		type: "GET",
+		url: "/compare?" + params,
+		dataType: "json",
+		success: function(data) {
+			if (!data.Ok) {
+				$("#compare").text("서버에 저장된 전적이 없습니다.");
+				return false;
+			}
+			$("#compare").html(createCompare(data));
+		},
+		error: function(xhr) {
+			$("#compare").text("비교 중 오류가 발생했습니다. " + xhr.responseText);
+		}
+	});
+}
+
+function createCompare(data) {
+	var ret = "<table class=\"table is-narrow is-bordered\"><thead><tr><th>주</th>";
+	for (var i = 0; i < data.Characters.length; i++) {
+		var c = data.Characters[i];
+		ret += "<th>" + $("<span>").text(c.Name).html() + " (" + c.WorldName + ")" +
+			(c.Found ? "" : "<br>기록 없음") + "</th>";
+	}
+	ret += "</tr></thead><tbody>";
+	for (var i = data.Weeks.length - 1; i >= 0; i--) {
+		var w = data.Weeks[i];
+		ret += "<tr><td>" + w.Week + "</td>";
+		for (var j = 0; j < w.Records.length; j++) {
+			var r = w.Records[j];
+			if (!r) {
+				ret += "<td>-</td>";
+				continue;
+			}
+			var best = $.inArray(j, w.Best || []) >= 0;
+			ret += "<td" + (best ? " class=\"is-selected\"" : "") + ">" + r.floor + " " + r.duration + "</td>";
+		}
+		ret += "</tr>";
+	}
+	ret += "</tbody><tfoot><tr><th>주간 1위</th>";
+	for (var i = 0; i < data.Characters.length; i++) {
+		ret += "<th>" + data.Characters[i].Wins + "회</th>";
+	}
+	return ret + "</tr></tfoot></table>";
+}
+
 function search(pushURLState) {
 	$("#result").text("전적 검색 중...");
 	if(pushURLState && !!(window.history && history.pushState)) {
-		var params = "?" + encodeURI($("#server").val() + ":" + $("#username").val());
+		var params = "?" + encodeURI($("#server").val() + ":" + $("#username").val() + ":" + $("#type").val());
 		history.pushState({
 			id: 'homepage'
 		}, document.getElementsByTagName("title")[0].innerHTML, window.location.href.substr(0, window.location.href.length - window.location.search.length) + params);
@@ -37,7 +161,7 @@ function search(pushURLState) {
 	$.ajax({
 		type: "POST",
 		url: "/getrank",
-		data: JSON.stringify({"World": parseInt($("#server").val(), 10), "Type": 2, "Name": $("#username").val()}),
+		data: JSON.stringify({"World": parseInt($("#server").val(), 10), "Type": parseInt($("#type").val(), 10), "Name": $("#username").val()}),
 		dataType: "json", 
 		contentType: "application/json",
 		success: function(data) {
@@ -48,6 +172,7 @@ function search(pushURLState) {
 				return false;
 			}
 			$("#result").html(createResult(data));
+			loadHistory();
 		},
 		error: function() {
 			$("#result").text("검색 중 오류가 발생했습니다.");
@@ -56,9 +181,42 @@ function search(pushURLState) {
 }
 
 
+function loadHistory() {
+	$.ajax({
+		type: "POST",
+		url: "/history",
+		data: JSON.stringify({"World": parseInt($("#server").val(), 10), "Type": parseInt($("#type").val(), 10), "Name": $("#username").val()}),
+		dataType: "json",
+		contentType: "application/json",
+		success: function(data) {
+			if (!data.Ok) {
+				return false;
+			}
+			$("#result").append(createHistory(data.History));
+		}
+	});
+}
+
+function createHistory(history) {
+	var chart = "/history.svg?world=" + encodeURIComponent($("#server").val()) +
+		"&type=" + encodeURIComponent($("#type").val()) +
+		"&name=" + encodeURIComponent($("#username").val());
+	var ret = "<br><br>[주간 기록]<br>" +
+		"<img src=\"" + chart + "\" alt=\"주간 기록 추이\"><br>" +
+		"<table class=\"table is-narrow is-striped\"><thead><tr>" +
+		"<th>주</th><th>닉네임</th><th>층</th><th>소요 시간</th><th>달성 날짜</th></tr></thead><tbody>";
+	for (var i = history.length - 1; i >= 0; i--) {
+		var date = new Date(history[i].checkedtime * 1000);
+		ret += "<tr><td>" + isoWeek(date) + "</td><td>" + $("<span>").text(history[i].nick).html() + "</td><td>" +
+			history[i].floor + "</td><td>" + history[i].duration + "</td><td>" + formatDate(date) + "</td></tr>";
+	}
+	return ret + "</tbody></table>";
+}
+
 function createResult(data) {
-	return "[최고 기록]<br>" + brief(data.MRank) +
-		"<br>[최근 기록]<br>" + brief(data.Rank) +
+	return createFormerNames(data.FormerNames) +
+		"[최고 기록]<br>" + brief(data.MRank) + briefContext(data.AllTime, "전체 기간") +
+		"<br>[최근 기록]<br>" + brief(data.Rank) + briefContext(data.Weekly, "해당 주") +
 		"<br>[추가 정보]<br>" +
 		"직업군: " + data.Rank.job + "<br>" + 
 		"세부직업: " + data.Rank.detail_job + "<br><br>" +
@@ -66,6 +224,29 @@ function createResult(data) {
 			formatDate(new Date(data.End * 1000));
 }
 
+function createFormerNames(names) {
+	if (!names || names.length == 0) {
+		return "";
+	}
+	var ret = "[이전 닉네임]<br>";
+	for (var i = names.length - 1; i >= 0; i--) {
+		ret += $("<span>").text(names[i].Name).html() + " (" + names[i].WorldName + ", " + names[i].Until + "까지)<br>";
+	}
+	return ret + "<br>";
+}
+
+function briefContext(ctx, label) {
+	if (!ctx || ctx.Total == 0) {
+		return "";
+	}
+	var ret = label + " 순위: " + ctx.Total + "명 중 " + ctx.Rank + "위 (상위 " + ctx.TopPercent.toFixed(1) + "%%)<br>" +
+		"같은 층 도달: " + ctx.SameFloor + "명<br>";
+	if (ctx.NextFloor > 0) {
+		ret += "다음 층(" + ctx.NextFloor + "층)까지: " + ctx.FloorGap + "층<br>";
+	}
+	return ret;
+}
+
 function brief(target) {
 	var date = new Date(target.checkedtime * 1000);
 	return "도달: " + target.floor + "<br>" +
@@ -82,16 +263,32 @@ function formatDate(date) {
 		<select name="server" id="server">
 			%s
 		</select>
+		<select name="type" id="type">
+			%s
+		</select>
 		<input type="submit" value="검색">
 		<br>
 	</form>
+	<form action="" id="statsfrm">
+		<select name="statsweek" id="statsweek">
+			<option value="all">전체 기간 최고 기록</option>
+		</select>
+		<label><input type="checkbox" id="statsall"> 모든 서버</label>
+		<input type="submit" value="직업별 통계">
+	</form>
+	<div id="stats"></div>
+	<form action="" id="comparefrm">
+		<input type="text" name="comparenames" id="comparenames" size="40" placeholder="닉네임1, 닉네임2, 서버:닉네임3">
+		<input type="submit" value="기록 비교">
+	</form>
+	<div id="compare"></div>
 	<div id="result">
 		탐색 결과는 여기에 표시됩니다. <br>
 		[주의]<br>
 		<font color="red">
 			정확한 탐색을 보증하지 않습니다.(Beta)<br>
 			전적 DB 시스템은 8시간마다 공식 홈페이지 랭킹을 수집하므로, 변경된 전적 반영에 최대 24+8시간 소요될 수 있습니다.<br>
-			전적 DB 시스템은 닉네임만으로 플레이어를 구분하므로 닉네임 변경에 취약합니다.<br>
+			닉네임 변경 및 서버 이전은 직업, 레벨, 경험치 등으로 추정하므로 잘못 연결될 수 있습니다.<br>
 		</font>
 		<font color="blue">달성 날짜는 최대 ±1일의 오차가 존재합니다.</font><br><br>
 		알림: 리부트 외 서버들에 대한 랭킹 수집을 지원합니다.<br>
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/cr0sh/dojangsearch/api"
+	"github.com/cr0sh/dojangsearch/storage"
+)
+
+// percentile 함수는 정렬된 values에서 p(0~100) 백분위 값을 nearest-rank 방식으로 반환합니다.
+func percentile(values []int, p int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	idx := (len(values)*p+99)/100 - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return values[idx]
+}
+
+// readJobStats 함수는 worlds 서버들의 기록을 세부 직업별로 집계합니다.
+// week가 비어 있으면 최고 기록을, 아니라면 해당 주의 기록을 사용합니다. 기록이 많은 직업부터 정렬합니다.
+func readJobStats(worlds []int, typeid int, week string) ([]api.JobStats, error) {
+	groups := map[string][]storage.Record{}
+	collect := func(rank storage.Record) error {
+		groups[rank.DetailJob] = append(groups[rank.DetailJob], rank)
+		return nil
+	}
+	for _, world := range worlds {
+		var err error
+		if week == "" {
+			err = store.ForEachMax(world, typeid, collect)
+		} else {
+			err = store.ForEachWeek(world, typeid, week, collect)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := make([]api.JobStats, 0, len(groups))
+	for detailJob, ranks := range groups {
+		floors := make([]int, len(ranks))
+		secs := make([]int, len(ranks))
+		best := ranks[0]
+		for i, rank := range ranks {
+			floors[i], secs[i] = rank.Floor, rank.FullSec()
+			if storage.Better(rank, best) {
+				best = rank
+			}
+		}
+		sort.Ints(floors)
+		sort.Ints(secs)
+		ret = append(ret, api.JobStats{
+			Job:         best.Job,
+			DetailJob:   detailJob,
+			Count:       len(ranks),
+			MedianFloor: percentile(floors, 50),
+			P25Floor:    percentile(floors, 25),
+			P75Floor:    percentile(floors, 75),
+			P90Floor:    percentile(floors, 90),
+			MedianSec:   percentile(secs, 50),
+			Best:        apiRecord(best),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Count != ret[j].Count {
+			return ret[i].Count > ret[j].Count
+		}
+		return ret[i].DetailJob < ret[j].DetailJob
+	})
+	return ret, nil
+}
+
+func handleJobStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	// world 파라미터가 없으면 모든 서버를 합쳐서 집계합니다.
+	world, worlds := 0, servers()
+	if s := q.Get("world"); s != "" {
+		var err error
+		if world, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "invalid world", http.StatusBadRequest)
+			return
+		}
+		worlds = []int{world}
+	}
+
+	typeid := categories()[0]
+	if s := q.Get("type"); s != "" {
+		var err error
+		if typeid, err = strconv.Atoi(s); err != nil {
+			http.Error(w, "invalid type", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// week 파라미터가 없거나 all 이면 전체 기간 최고 기록으로 집계합니다.
+	week := q.Get("week")
+	if week == "all" {
+		week = ""
+	}
+
+	var response api.JobStatsResponse
+	response.World, response.Type, response.Week = world, typeid, week
+
+	var err error
+	if response.Classes, err = readJobStats(worlds, typeid, week); err != nil {
+		errLog.Println("HTTP: readJobStats failed:", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	for _, c := range response.Classes {
+		response.Total += c.Count
+	}
+	response.Ok = true
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		errLog.Println("HTTP: Response encode failed:", err)
+	}
+}
@@ -5,13 +5,27 @@ import (
 	"net/http"
 )
 
-var cachedWebContent string = fmt.Sprintf(webcontent, func() string {
-	ret := ""
-	for _, world := range serverList {
-		ret += fmt.Sprintf("<option value=\"%d\">%s</option>\n", world, serverName[world])
+var cachedWebContent string // worldsLock을 잡고 접근합니다.
+
+func webContent() string {
+	worldsLock.RLock()
+	defer worldsLock.RUnlock()
+	return cachedWebContent
+}
+
+// buildWebContent 함수는 서버 및 카테고리 목록으로 검색 페이지를 생성합니다.
+// 설정이 적용될 때마다 applyConfig에서 호출됩니다.
+func buildWebContent() string {
+	worlds := ""
+	for _, world := range servers() {
+		worlds += fmt.Sprintf("<option value=\"%d\">%s</option>\n", world, serverNameOf(world))
 	}
-	return ret
-}())
+	cats := ""
+	for _, typeid := range categories() {
+		cats += fmt.Sprintf("<option value=\"%d\">%s</option>\n", typeid, categoryNameOf(typeid))
+	}
+	return fmt.Sprintf(webcontent, worlds, cats)
+}
 
 func init() {
 	http.HandleFunc("/jquery.js", func(w http.ResponseWriter, r *http.Request) {
@@ -25,6 +39,6 @@ func init() {
 	})
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(cachedWebContent))
+		w.Write([]byte(webContent()))
 	})
 }
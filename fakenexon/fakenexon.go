@@ -0,0 +1,121 @@
+// Package fakenexon 은 넥슨 모바일 무릉도장 랭킹 JSON API를 흉내내는 로컬 서버입니다.
+// 녹화된 페이지를 그대로 재생하므로 실제 사이트 없이 크롤러를 실행할 수 있습니다.
+package fakenexon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+)
+
+const (
+	ThisWeekPath = "/MapleStory/Data/Json/Ranking/DojangThisWeekListJson.aspx"
+	LastWeekPath = "/MapleStory/Data/Json/Ranking/DojangLastWeekListJson.aspx"
+)
+
+// Key 는 랭킹 페이지 요청을 구분합니다.
+type Key struct {
+	LastWeek bool
+	World    int
+	Type     int
+	RankIdx  int
+}
+
+// Response 는 재생할 HTTP 응답 하나입니다. Status가 0이면 200으로 응답합니다.
+type Response struct {
+	Status int
+	Body   string
+}
+
+// Server 는 httptest.Server 위에서 동작하는 가짜 랭킹 서버입니다.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[Key][]Response
+	hits      map[Key]int
+}
+
+// NewServer 함수는 녹화된 기본 페이지(Recorded)를 담은 서버를 시작합니다.
+func NewServer() *Server {
+	s := NewEmptyServer()
+	for k, v := range Recorded {
+		s.Set(k, v...)
+	}
+	return s
+}
+
+// NewEmptyServer 함수는 등록된 페이지가 없는 서버를 시작합니다.
+// 등록되지 않은 요청에는 빈 list로 응답합니다.
+func NewEmptyServer() *Server {
+	s := &Server{
+		responses: make(map[Key][]Response),
+		hits:      make(map[Key]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Set 함수는 해당 요청에 대한 응답을 등록합니다. 같은 요청이 반복되면 응답을 차례로 재생하며,
+// 마지막 응답은 그 이후의 요청에도 계속 사용됩니다. 재시도 동작을 재현할 때 유용합니다.
+func (s *Server) Set(k Key, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[k] = responses
+	delete(s.hits, k)
+}
+
+// Hits 함수는 해당 요청을 받은 횟수를 반환합니다.
+func (s *Server) Hits(k Key) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[k]
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var k Key
+	switch r.URL.Path {
+	case ThisWeekPath:
+	case LastWeekPath:
+		k.LastWeek = true
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	q := r.URL.Query()
+	var err error
+	if k.World, err = strconv.Atoi(q.Get("GameWorldID")); err != nil {
+		http.Error(w, "invalid GameWorldID", http.StatusBadRequest)
+		return
+	}
+	if k.Type, err = strconv.Atoi(q.Get("cateType")); err != nil {
+		http.Error(w, "invalid cateType", http.StatusBadRequest)
+		return
+	}
+	if k.RankIdx, err = strconv.Atoi(q.Get("rankidx")); err != nil {
+		http.Error(w, "invalid rankidx", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	responses := s.responses[k]
+	n := s.hits[k]
+	s.hits[k]++
+	s.mu.Unlock()
+
+	resp := Response{Body: EmptyPage}
+	if len(responses) > 0 {
+		if n >= len(responses) {
+			n = len(responses) - 1
+		}
+		resp = responses[n]
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if resp.Status != 0 {
+		w.WriteHeader(resp.Status)
+	}
+	w.Write([]byte(resp.Body))
+}
@@ -0,0 +1,38 @@
+package fakenexon
+
+// EmptyPage 는 마지막 페이지를 지났을 때의 응답입니다.
+const EmptyPage = `{"result":"S","list":[],"nextidx":"0"}`
+
+// MalformedPage 는 중간에 잘린 응답입니다.
+const MalformedPage = `{"result":"S","list":[{"rank":"1","move":"0","icon":"`
+
+const rebootPage1 = `{"result":"S","list":[
+{"rank":"1","move":"0","icon":"http://avatar.maplestory.nexon.com/Character/180/AAAA.png","nick":"무릉고수","job":"전사","detail_job":"히어로","level":235,"exp":1820000000,"popular":120,"floor":"61층","duration":"13분 2초","guild_worldid":"1"},
+{"rank":"2","move":"1","icon":"http://avatar.maplestory.nexon.com/Character/180/BBBB.png","nick":"Bishop","job":"마법사","detail_job":"비숍","level":230,"exp":990000000,"popular":45,"floor":"61층","duration":"14분 40초","guild_worldid":"1"}
+],"nextidx":"3"}`
+
+const rebootPage2 = `{"result":"S","list":[
+{"rank":"3","move":"-1","icon":"http://avatar.maplestory.nexon.com/Character/180/CCCC.png","nick":"활쟁이","job":"궁수","detail_job":"보우마스터","level":221,"exp":120000000,"popular":3,"floor":"55층","duration":"9분 5초","guild_worldid":"1"}
+],"nextidx":"4"}`
+
+const reboot2Page1 = `{"result":"S","list":[
+{"rank":"1","move":"0","icon":"http://avatar.maplestory.nexon.com/Character/180/DDDD.png","nick":"표도","job":"도적","detail_job":"나이트로드","level":240,"exp":5000000000,"popular":900,"floor":"63층","duration":"14분 59초","guild_worldid":"12"}
+],"nextidx":"2"}`
+
+const rebootLastWeekPage1 = `{"result":"S","list":[
+{"rank":"1","move":"0","icon":"http://avatar.maplestory.nexon.com/Character/180/AAAA.png","nick":"무릉고수","job":"전사","detail_job":"히어로","level":235,"exp":1700000000,"popular":118,"floor":"60층","duration":"12분 10초","guild_worldid":"1"}
+],"nextidx":"2"}`
+
+// Recorded 는 NewServer가 재생하는 녹화된 페이지입니다.
+// 리부트2 서버의 첫 페이지는 잘린 응답 뒤에 정상 응답을 돌려주어 재시도를 재현합니다.
+var Recorded = map[Key][]Response{
+	{World: 1, Type: 2, RankIdx: 1}: {{Body: rebootPage1}},
+	{World: 1, Type: 2, RankIdx: 3}: {{Body: rebootPage2}},
+	{World: 1, Type: 2, RankIdx: 4}: {{Body: EmptyPage}},
+
+	{World: 12, Type: 2, RankIdx: 1}: {{Body: MalformedPage}, {Status: 500, Body: "Internal Server Error"}, {Body: reboot2Page1}},
+	{World: 12, Type: 2, RankIdx: 2}: {{Body: EmptyPage}},
+
+	{LastWeek: true, World: 1, Type: 2, RankIdx: 1}: {{Body: rebootLastWeekPage1}},
+	{LastWeek: true, World: 1, Type: 2, RankIdx: 2}: {{Body: EmptyPage}},
+}
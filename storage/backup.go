@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+const snapshotPrefix = "database-"
+const snapshotSuffix = ".db"
+
+// snapshotLock 은 예약된 백업과 관리자 요청이 동시에 사본을 만들 때 같은 이름을 고르지 않도록 합니다.
+var snapshotLock sync.Mutex
+
+// uniquePath 함수는 base+suffix 경로가 이미 있으면 base-1+suffix, base-2+suffix ... 중 없는 경로를 반환합니다.
+func uniquePath(base, suffix string) string {
+	path := base + suffix
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = base + "-" + strconv.Itoa(i) + suffix
+	}
+}
+
+// WriteTo 함수는 읽기 트랜잭션 안에서 DB 전체의 일관된 사본을 w에 씁니다.
+// 쓰는 동안에도 다른 읽기, 쓰기 작업을 막지 않습니다.
+func (s *Store) WriteTo(w io.Writer) (n int64, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		n, err = tx.WriteTo(w)
+		return err
+	})
+	return n, err
+}
+
+// Snapshot 함수는 dir에 DB 사본을 만들고, 가장 최근 keep개를 제외한 예전 사본을 지웁니다.
+// keep이 0 이하이면 예전 사본을 지우지 않습니다. 만든 사본의 경로를 반환합니다.
+func (s *Store) Snapshot(dir string, keep int) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	f, err := ioutil.TempFile(dir, "snapshot-")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+	if _, err := s.WriteTo(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	// 같은 초에 사본을 여러 번 만들 수 있으므로 마이크로초까지 넣고, 그래도 겹치면 번호를 붙입니다.
+	snapshotLock.Lock()
+	path := uniquePath(filepath.Join(dir, snapshotPrefix+time.Now().Format("20060102-150405.000000")), snapshotSuffix)
+	err = os.Rename(tmp, path)
+	snapshotLock.Unlock()
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	if keep > 0 {
+		if err := rotateSnapshots(dir, keep); err != nil {
+			return path, err
+		}
+	}
+	return path, nil
+}
+
+func rotateSnapshots(dir string, keep int) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, fi := range files {
+		if !fi.IsDir() && strings.HasPrefix(fi.Name(), snapshotPrefix) && strings.HasSuffix(fi.Name(), snapshotSuffix) {
+			names = append(names, fi.Name())
+		}
+	}
+	// 파일 이름에 시각이 들어 있으므로 이름 순서가 곧 시간 순서입니다.
+	sort.Strings(names)
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return err
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// Verify 함수는 사본 파일을 읽기 전용으로 열어 페이지 구조의 무결성과 스키마 버전을 확인합니다.
+func Verify(path string) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		// Check의 채널은 끝까지 읽어야 검사 고루틴과 읽기 트랜잭션이 끝납니다.
+		var first error
+		count := 0
+		for err := range tx.Check() {
+			if first == nil {
+				first = err
+			}
+			count++
+		}
+		if first != nil {
+			return fmt.Errorf("integrity check: %d errors, first: %v", count, first)
+		}
+		if version := schemaVersion(tx); version != SchemaVersion {
+			return fmt.Errorf("%w: found %d, want %d", ErrSchemaVersion, version, SchemaVersion)
+		}
+		return nil
+	})
+}
+
+// Restore 함수는 snapshot을 검증한 뒤 path의 DB를 교체합니다. 기존 DB가 있었다면
+// path.<시각>.bak 으로 남기고 그 경로를 backup으로 반환하며, 없었다면 backup은 빈 문자열입니다.
+// 예전 .bak 파일은 덮어쓰지 않습니다. path의 DB를 다른 프로세스가 열고 있다면 실패합니다.
+func Restore(snapshot, path string) (backup string, err error) {
+	if err := Verify(snapshot); err != nil {
+		return "", fmt.Errorf("%s: %v", snapshot, err)
+	}
+
+	// 서버가 DB를 열고 있는지 파일 잠금으로 확인합니다.
+	if _, err := os.Stat(path); err == nil {
+		db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+		if err != nil {
+			return "", fmt.Errorf("%s is in use: %v", path, err)
+		}
+		db.Close()
+	}
+
+	src, err := os.Open(snapshot)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	tmp := path + ".restore"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := dst.Sync(); err != nil {
+		dst.Close()
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := Verify(tmp); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("%s: %v", tmp, err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		backup = uniquePath(path+"."+time.Now().Format("20060102-150405"), ".bak")
+		if err := os.Rename(path, backup); err != nil {
+			os.Remove(tmp)
+			return "", err
+		}
+	}
+	return backup, os.Rename(tmp, path)
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotSameSecond(t *testing.T) {
+	s := openTestStore(t)
+	dir := t.TempDir()
+
+	seen := map[string]bool{}
+	for i := 0; i < 5; i++ {
+		path, err := s.Snapshot(dir, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[path] {
+			t.Fatalf("snapshot %d reused path %s", i, path)
+		}
+		seen[path] = true
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 5 {
+		t.Errorf("%d snapshot files, want 5", len(files))
+	}
+}
+
+func TestRestoreKeepsEveryBackup(t *testing.T) {
+	s := openTestStore(t)
+	if err := s.PutWeekResults(1, 2, []Record{testRecord("a", 50, 10, 0)}, time.Date(2018, 3, 7, 7, 0, 0, 0, kst), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := s.Snapshot(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "database.db")
+	backup, err := Restore(snapshot, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup != "" {
+		t.Errorf("restore without a previous DB reported backup %s", backup)
+	}
+
+	backups := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		backup, err := Restore(snapshot, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if backup == "" || backups[backup] {
+			t.Fatalf("restore %d: backup = %q, want a new path", i, backup)
+		}
+		backups[backup] = true
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("backups = %v, want 2 files", matches)
+	}
+}
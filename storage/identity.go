@@ -0,0 +1,395 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// 캐릭터 정보는 모든 서버, 카테고리가 함께 쓰는 characters 버킷에 ID를 키로 저장됩니다.
+// characters-by-job 버킷은 카테고리, 직업, 세부 직업별로 캐릭터 ID를 모은 색인입니다.
+var charactersBucket = []byte("characters")
+var jobIndexBucket = []byte("characters-by-job")
+
+// maxLevelGainPerWeek 는 같은 캐릭터로 볼 수 있는 주당 최대 레벨 상승 폭입니다.
+const maxLevelGainPerWeek = 10
+
+// FormerName 은 캐릭터가 예전에 쓰던 닉네임입니다.
+type FormerName struct {
+	Name  string
+	World int
+	Until string // 이 닉네임으로 마지막으로 랭킹에 오른 주 (WeekKey 형식)
+}
+
+// Identity 는 닉네임이 바뀌거나 서버를 옮겨도 유지되는 캐릭터 정보입니다.
+// 같은 카테고리 안에서 직업, 레벨, 경험치와 아이콘을 비교해 같은 캐릭터를 찾습니다.
+type Identity struct {
+	ID          uint64
+	World       int
+	Type        int
+	Name        string       // 현재 닉네임. 다른 캐릭터가 가져갔다면 마지막으로 쓰던 닉네임입니다.
+	FormerNames []FormerName `json:",omitempty"`
+	Job         string
+	DetailJob   string
+	Level       int64
+	Exp         int64
+	IconURL     string
+	Seen        int64 // 마지막으로 랭킹에 오른 기록의 달성 시각(유닉스 시각)
+}
+
+func idKey(id uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, id)
+	return buf
+}
+
+func getIdentity(bc *bolt.Bucket, id []byte) (*Identity, error) {
+	buf := bc.Get(id)
+	if buf == nil {
+		return nil, nil
+	}
+	var c Identity
+	if err := json.Unmarshal(buf, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func putIdentity(bc *bolt.Bucket, c *Identity) error {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return bc.Put(idKey(c.ID), buf)
+}
+
+// jobPrefix 함수는 직업 색인에서 typeid 카테고리, job, detailJob에 해당하는 키의 접두사를 반환합니다.
+// 키는 접두사 뒤에 8바이트 캐릭터 ID가 붙은 형식입니다.
+func jobPrefix(typeid int, job, detailJob string) []byte {
+	buf := make([]byte, 4, 4+len(job)+len(detailJob)+2)
+	binary.BigEndian.PutUint32(buf, uint32(typeid))
+	buf = append(buf, job...)
+	buf = append(buf, 0)
+	buf = append(buf, detailJob...)
+	return append(buf, 0)
+}
+
+// indexJob 함수는 캐릭터를 직업 색인에 추가합니다. 같은 캐릭터로 이어지려면 직업이 같아야 하므로
+// 캐릭터의 직업은 바뀌지 않으며, 색인은 캐릭터를 만들 때 한 번만 추가하면 됩니다.
+func indexJob(bj *bolt.Bucket, c *Identity) error {
+	return bj.Put(append(jobPrefix(c.Type, c.Job, c.DetailJob), idKey(c.ID)...), nil)
+}
+
+// jobIndex 함수는 직업 색인 버킷을 반환합니다. 색인이 없는 DB라면 characters 버킷에서 새로 만듭니다.
+func jobIndex(tx *bolt.Tx, bc *bolt.Bucket) (*bolt.Bucket, error) {
+	if bj := tx.Bucket(jobIndexBucket); bj != nil {
+		return bj, nil
+	}
+	bj, err := tx.CreateBucket(jobIndexBucket)
+	if err != nil {
+		return nil, err
+	}
+	return bj, bc.ForEach(func(k, v []byte) error {
+		var c Identity
+		if err := json.Unmarshal(v, &c); err != nil {
+			return err
+		}
+		return indexJob(bj, &c)
+	})
+}
+
+// update 함수는 새 기록으로 캐릭터 정보를 갱신합니다. 닉네임이나 서버가 바뀌었다면 예전 닉네임을 남깁니다.
+// 이미 저장된 정보보다 오래된 기록(예: 이번 주 수집 뒤의 지난주 수집)으로는 갱신하지 않습니다.
+func (c *Identity) update(world int, r Record, seen time.Time) {
+	if c.Name != "" && seen.Unix() < c.Seen {
+		return
+	}
+	if c.Name != "" && (c.Name != r.Name || c.World != world) {
+		c.FormerNames = append(c.FormerNames, FormerName{
+			Name:  c.Name,
+			World: c.World,
+			Until: WeekKey(time.Unix(c.Seen, 0)),
+		})
+	}
+	c.World, c.Name = world, r.Name
+	c.Job, c.DetailJob = r.Job, r.DetailJob
+	c.Level, c.Exp, c.IconURL = r.Level, r.Exp, r.IconURL
+	c.Seen = seen.Unix()
+}
+
+// matches 함수는 r이 c와 같은 캐릭터의 기록일 수 있는지 반환합니다.
+// 직업이 같아야 하며, 두 기록을 시간 순서대로 놓았을 때 레벨이 줄지 않고 그 사이 기간에 비해 지나치게 오르지 않아야 합니다.
+// 사망하면 경험치를 잃으므로 같은 레벨 안에서의 경험치 차이는 허용합니다. r은 c보다 오래된 기록일 수도 있습니다.
+// strong은 아이콘까지 같아 같은 캐릭터임이 거의 확실한 경우입니다.
+func (c *Identity) matches(r Record, seen time.Time) (ok, strong bool) {
+	if c.Job != r.Job || c.DetailJob != r.DetailJob {
+		return false, false
+	}
+	from, to := time.Unix(c.Seen, 0), seen
+	before, after := c.Level, r.Level
+	if seen.Unix() < c.Seen {
+		from, to = to, from
+		before, after = after, before
+	}
+	if after < before {
+		return false, false
+	}
+	weeks := int64(to.Sub(from)/(time.Hour*24*7)) + 1
+	if after-before > maxLevelGainPerWeek*weeks {
+		return false, false
+	}
+	return true, c.IconURL != "" && c.IconURL == r.IconURL
+}
+
+// findIdentity 함수는 새 닉네임 r과 같은 캐릭터로 보이는 후보를 찾습니다.
+// 아이콘까지 같은 후보가 하나뿐이면 그 후보를, 그런 후보가 없다면 같은 서버의 후보가 하나뿐일 때 그 후보를 고릅니다.
+// 서버를 옮긴 캐릭터는 아이콘이 같을 때만 찾으며, 두 아이콘이 모두 있는데 다르다면 다른 캐릭터로 봅니다.
+// 애매하면 nil을 반환합니다.
+func findIdentity(candidates []*Identity, world int, r Record, seen time.Time) *Identity {
+	var strong, weak []*Identity
+	for _, c := range candidates {
+		ok, s := c.matches(r, seen)
+		switch {
+		case !ok:
+		case c.IconURL != "" && r.IconURL != "" && c.IconURL != r.IconURL:
+		case s:
+			strong = append(strong, c)
+		case c.World == world:
+			weak = append(weak, c)
+		}
+	}
+	if len(strong) == 1 {
+		return strong[0]
+	}
+	if len(strong) == 0 && len(weak) == 1 {
+		return weak[0]
+	}
+	return nil
+}
+
+// loadCandidates 함수는 typeid 카테고리에서 직업과 세부 직업이 job, detailJob인 캐릭터 중 used에 없는 캐릭터를 읽습니다.
+// 닉네임을 다른 캐릭터에 내주지 않았고 seen과 같은 주에 이미 수집된 캐릭터는 지금도 그 닉네임으로 남아 있으므로 제외합니다.
+func loadCandidates(tx *bolt.Tx, bc, bj *bolt.Bucket, typeid int, job, detailJob string, used map[uint64]bool, seen time.Time) ([]*Identity, error) {
+	var ret []*Identity
+	prefix := jobPrefix(typeid, job, detailJob)
+	cur := bj.Cursor()
+	for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		id := k[len(prefix):]
+		if len(id) != 8 || used[binary.BigEndian.Uint64(id)] {
+			continue
+		}
+		c, err := getIdentity(bc, id)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			continue
+		}
+		if WeekKey(time.Unix(c.Seen, 0).In(seen.Location())) == WeekKey(seen) {
+			if bn := tx.Bucket(bucketName(kindNames, c.World, c.Type)); bn != nil && string(bn.Get(nameKey(c.Name))) == string(id) {
+				continue
+			}
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+// moveCharacter 함수는 서버를 옮긴 캐릭터의 기록을 새 서버의 버킷으로 옮기고, 예전 서버의 닉네임을 지웁니다.
+func moveCharacter(tx *bolt.Tx, c *Identity, to int) error {
+	from, id := c.World, idKey(c.ID)
+
+	if bn := tx.Bucket(bucketName(kindNames, from, c.Type)); bn != nil {
+		if k := nameKey(c.Name); string(bn.Get(k)) == string(id) {
+			if err := bn.Delete(k); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, kind := range []string{kindRecent, kindMax} {
+		src := tx.Bucket(bucketName(kind, from, c.Type))
+		if src == nil {
+			continue
+		}
+		buf := src.Get(id)
+		if buf == nil {
+			continue
+		}
+		dst, err := tx.CreateBucketIfNotExists(bucketName(kind, to, c.Type))
+		if err != nil {
+			return err
+		}
+		if err := dst.Put(id, buf); err != nil {
+			return err
+		}
+		if err := src.Delete(id); err != nil {
+			return err
+		}
+	}
+
+	src := tx.Bucket(bucketName(kindHistory, from, c.Type))
+	if src == nil || src.Bucket(id) == nil {
+		return nil
+	}
+	dst, err := tx.CreateBucketIfNotExists(bucketName(kindHistory, to, c.Type))
+	if err != nil {
+		return err
+	}
+	dc, err := dst.CreateBucketIfNotExists(id)
+	if err != nil {
+		return err
+	}
+	if err := src.Bucket(id).ForEach(func(k, v []byte) error {
+		return dc.Put(k, v)
+	}); err != nil {
+		return err
+	}
+	return src.DeleteBucket(id)
+}
+
+// relink 함수는 닉네임이나 서버가 바뀐 캐릭터 c를 world 서버의 name 닉네임으로 옮깁니다.
+// 예전 닉네임 색인을 지우고, 최고 기록도 새 닉네임으로 보이도록 합니다. 주별 기록에는 당시 닉네임이 남습니다.
+func relink(tx *bolt.Tx, bn *bolt.Bucket, c *Identity, world, typeid int, name string) error {
+	if c.World != world {
+		if err := moveCharacter(tx, c, world); err != nil {
+			return err
+		}
+	} else if k := nameKey(c.Name); string(bn.Get(k)) == string(idKey(c.ID)) {
+		if err := bn.Delete(k); err != nil {
+			return err
+		}
+	}
+	return renameMax(tx, world, typeid, c.ID, name)
+}
+
+// resolveIdentities 함수는 한 서버, 카테고리의 수집 결과 각각에 캐릭터 ID를 정합니다.
+// 먼저 닉네임이 그대로인 캐릭터를 찾고, 남은 기록은 닉네임을 바꿨거나 서버를 옮긴 캐릭터를 찾아 잇습니다.
+// 그래도 찾지 못하면 새 ID를 부여합니다. 닉네임 색인과 캐릭터 정보는 여기서 갱신됩니다.
+func resolveIdentities(tx *bolt.Tx, world, typeid int, records []Record, seen time.Time) ([]uint64, error) {
+	bn, err := tx.CreateBucketIfNotExists(bucketName(kindNames, world, typeid))
+	if err != nil {
+		return nil, err
+	}
+	bc, err := tx.CreateBucketIfNotExists(charactersBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint64, len(records))
+	idents := make([]*Identity, len(records))
+	used := map[uint64]bool{}
+	for i, r := range records {
+		key := nameKey(r.Name)
+		id := bn.Get(key)
+		if id == nil {
+			continue
+		}
+		c, err := getIdentity(bc, id)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil && !used[c.ID] {
+			if ok, _ := c.matches(r, seen); ok {
+				ids[i], idents[i], used[c.ID] = c.ID, c, true
+				continue
+			}
+		}
+		// 다른 캐릭터가 닉네임을 가져갔습니다. 예전 캐릭터는 닉네임 없이 남아 나중에 다시 이어질 수 있습니다.
+		if err := bn.Delete(key); err != nil {
+			return nil, err
+		}
+	}
+
+	bj, err := jobIndex(tx, bc)
+	if err != nil {
+		return nil, err
+	}
+	// 후보는 직업별로 필요할 때 한 번만 읽습니다.
+	candidates := map[string][]*Identity{}
+	created := make([]bool, len(records))
+	for i, r := range records {
+		if ids[i] != 0 {
+			continue
+		}
+		group := string(jobPrefix(typeid, r.Job, r.DetailJob))
+		if _, ok := candidates[group]; !ok {
+			if candidates[group], err = loadCandidates(tx, bc, bj, typeid, r.Job, r.DetailJob, used, seen); err != nil {
+				return nil, err
+			}
+		}
+
+		c := findIdentity(candidates[group], world, r, seen)
+		// 이미 저장된 정보보다 오래된 기록이라면 ID만 잇고 닉네임 색인과 서버는 그대로 둡니다.
+		// 그 뒤에 다른 서버로 옮긴 캐릭터라면 기록이 두 서버에 나뉘지 않도록 잇지 않습니다.
+		stale := c != nil && seen.Unix() < c.Seen
+		if stale && c.World != world {
+			c, stale = nil, false
+		}
+		if c != nil {
+			if !stale {
+				if err := relink(tx, bn, c, world, typeid, r.Name); err != nil {
+					return nil, err
+				}
+			}
+			cands := candidates[group]
+			for j, cand := range cands {
+				if cand == c {
+					candidates[group] = append(cands[:j], cands[j+1:]...)
+					break
+				}
+			}
+		} else {
+			id, err := bc.NextSequence()
+			if err != nil {
+				return nil, err
+			}
+			c = &Identity{ID: id, Type: typeid}
+			created[i] = true
+		}
+		ids[i], idents[i], used[c.ID] = c.ID, c, true
+		if stale {
+			continue
+		}
+		if err := bn.Put(nameKey(r.Name), idKey(c.ID)); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, r := range records {
+		idents[i].update(world, r, seen)
+		if err := putIdentity(bc, idents[i]); err != nil {
+			return nil, err
+		}
+		if created[i] {
+			if err := indexJob(bj, idents[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ids, nil
+}
+
+func renameMax(tx *bolt.Tx, world, typeid int, id uint64, name string) error {
+	bm := tx.Bucket(bucketName(kindMax, world, typeid))
+	if bm == nil {
+		return nil
+	}
+	buf := bm.Get(idKey(id))
+	if buf == nil {
+		return nil
+	}
+	var r Record
+	if err := json.Unmarshal(buf, &r); err != nil {
+		return err
+	}
+	r.Name = name
+	buf, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return bm.Put(idKey(id), buf)
+}
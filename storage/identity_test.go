@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+func getTestCharacter(t *testing.T, s *Store, name string) *Character {
+	t.Helper()
+	c, err := s.GetCharacter(1, 2, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == nil {
+		t.Fatalf("GetCharacter(%q) = nil", name)
+	}
+	return c
+}
+
+func TestIdentityExpLoss(t *testing.T) {
+	s := openTestStore(t)
+	week1 := time.Date(2018, 3, 7, 7, 0, 0, 0, kst)
+
+	r := testRecord("dead", 50, 10, 0)
+	if err := s.PutWeekResults(1, 2, []Record{r}, week1, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	before := getTestCharacter(t, s, "dead")
+
+	// 사망해서 같은 레벨 안에서 경험치가 줄었습니다.
+	r.Exp = 10
+	if err := s.PutWeekResults(1, 2, []Record{r}, week1.AddDate(0, 0, 7), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	after := getTestCharacter(t, s, "dead")
+	if after.ID != before.ID {
+		t.Fatalf("ID changed after EXP loss: %d -> %d", before.ID, after.ID)
+	}
+	if history, err := s.History(1, 2, "dead"); err != nil || len(history) != 2 {
+		t.Errorf("history = %+v, %v, want 2 weeks", history, err)
+	}
+
+	// 레벨은 줄어들 수 없으므로 다른 캐릭터입니다.
+	r.Level--
+	if err := s.PutWeekResults(1, 2, []Record{r}, week1.AddDate(0, 0, 14), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	if c := getTestCharacter(t, s, "dead"); c.ID == before.ID {
+		t.Errorf("record with lower level kept ID %d", c.ID)
+	}
+}
+
+func TestIdentityOlderData(t *testing.T) {
+	s := openTestStore(t)
+	now := time.Date(2018, 3, 7, 7, 0, 0, 0, kst)
+
+	thisWeek := testRecord("late", 61, 10, 0)
+	thisWeek.Level, thisWeek.Exp = 237, 100
+	if err := s.PutWeekResults(1, 2, []Record{thisWeek}, now, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	before := getTestCharacter(t, s, "late")
+
+	// 이번 주를 먼저 수집한 뒤 지난주를 수집하면 레벨, 경험치가 더 낮은 기록이 들어옵니다.
+	lastWeek := testRecord("late", 60, 11, 0)
+	lastWeek.Level, lastWeek.Exp = 235, 5000
+	if err := s.PutWeekResults(1, 2, []Record{lastWeek}, now, LastWeek); err != nil {
+		t.Fatal(err)
+	}
+	after := getTestCharacter(t, s, "late")
+	if after.ID != before.ID {
+		t.Fatalf("ID changed after crawling last week: %d -> %d", before.ID, after.ID)
+	}
+	if after.Level != 237 || after.Exp != 100 {
+		t.Errorf("identity = Lv %d, Exp %d, want the newer Lv 237, Exp 100", after.Level, after.Exp)
+	}
+	if after.Max.Floor != 61 {
+		t.Errorf("max floor = %d, want 61", after.Max.Floor)
+	}
+	history, err := s.History(1, 2, "late")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Floor != 60 || history[1].Floor != 61 {
+		t.Errorf("history = %+v, want floors 60, 61", history)
+	}
+}
+
+func TestIdentityRenameByJob(t *testing.T) {
+	s := openTestStore(t)
+	week1 := time.Date(2018, 3, 7, 7, 0, 0, 0, kst)
+
+	hero := testRecord("old", 50, 10, 0)
+	mage := testRecord("mage", 40, 10, 0)
+	mage.Job, mage.DetailJob, mage.IconURL = "마법사", "비숍", "icon-old"
+	if err := s.PutWeekResults(1, 2, []Record{hero, mage}, week1, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	old := getTestCharacter(t, s, "old")
+
+	// 닉네임을 바꾼 캐릭터는 같은 직업의 후보 중에서 찾습니다.
+	hero.Name = "new"
+	if err := s.PutWeekResults(1, 2, []Record{hero}, week1.AddDate(0, 0, 7), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	renamed := getTestCharacter(t, s, "new")
+	if renamed.ID != old.ID {
+		t.Errorf("renamed character got ID %d, want %d", renamed.ID, old.ID)
+	}
+	if len(renamed.FormerNames) != 1 || renamed.FormerNames[0].Name != "old" {
+		t.Errorf("former names = %+v, want old", renamed.FormerNames)
+	}
+
+	// 색인이 없는 DB에서는 characters 버킷에서 색인을 다시 만듭니다.
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(jobIndexBucket)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	hero.Name = "newer"
+	if err := s.PutWeekResults(1, 2, []Record{hero}, week1.AddDate(0, 0, 14), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	if c := getTestCharacter(t, s, "newer"); c.ID != old.ID {
+		t.Errorf("renamed character after rebuilding the index got ID %d, want %d", c.ID, old.ID)
+	}
+}
+
+func TestIdentityNewcomerDoesNotTakeOver(t *testing.T) {
+	s := openTestStore(t)
+	week1 := time.Date(2018, 3, 7, 7, 0, 0, 0, kst)
+
+	if err := s.PutWeekResults(1, 2, []Record{testRecord("veteran", 70, 10, 0)}, week1, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	veteran := getTestCharacter(t, s, "veteran")
+
+	// 다음 주에 veteran은 랭킹에 없고, 아이콘이 다른 같은 직업의 새 캐릭터가 나타났습니다.
+	if err := s.PutWeekResults(1, 2, []Record{testRecord("newbie", 40, 10, 0)}, week1.AddDate(0, 0, 7), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	newbie := getTestCharacter(t, s, "newbie")
+	if newbie.ID == veteran.ID || len(newbie.FormerNames) != 0 || newbie.Max.Floor != 40 {
+		t.Errorf("newbie = ID %d, former names %+v, max %d, want a new ID with its own max 40", newbie.ID, newbie.FormerNames, newbie.Max.Floor)
+	}
+	if c := getTestCharacter(t, s, "veteran"); c.ID != veteran.ID || c.Max.Floor != 70 {
+		t.Errorf("veteran = ID %d, max %d, want ID %d, max 70", c.ID, c.Max.Floor, veteran.ID)
+	}
+}
+
+func TestIdentitySameWeekNotCandidate(t *testing.T) {
+	s := openTestStore(t)
+	crawl := time.Date(2018, 3, 7, 7, 0, 0, 0, kst)
+
+	present := testRecord("present", 60, 10, 0)
+	present.IconURL = ""
+	if err := s.PutWeekResults(1, 2, []Record{present}, crawl, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	before := getTestCharacter(t, s, "present")
+
+	// 같은 주의 다음 수집에서 present가 빠졌더라도 닉네임을 그대로 가진 캐릭터이므로 후보가 아닙니다.
+	other := testRecord("other", 50, 10, 0)
+	other.IconURL = ""
+	if err := s.PutWeekResults(1, 2, []Record{other}, crawl.AddDate(0, 0, 1), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	if c := getTestCharacter(t, s, "other"); c.ID == before.ID {
+		t.Errorf("other took the ID %d of a character seen this week", c.ID)
+	}
+	if c := getTestCharacter(t, s, "present"); c.ID != before.ID {
+		t.Errorf("present = ID %d, want %d", c.ID, before.ID)
+	}
+}
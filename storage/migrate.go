@@ -0,0 +1,437 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// SchemaVersion 은 현재 DB 스키마 버전입니다.
+//
+//	1: 버전 키가 없는 예전 DB. 일부 키가 닉네임 대소문자를 유지한 채 저장되어 있습니다.
+//	2: 모든 캐릭터 키가 소문자 닉네임입니다.
+//	3: 기록 버킷의 키가 캐릭터 ID이며, 닉네임은 names 버킷으로 찾습니다.
+const SchemaVersion = 3
+
+var schemaBucket = []byte("schema")
+var versionKey = []byte("version")
+
+// ErrSchemaVersion 은 DB 스키마 버전이 현재 버전과 다를 때 반환됩니다. migrate 명령으로 갱신해야 합니다.
+var ErrSchemaVersion = errors.New("storage: database schema version mismatch")
+
+var errDryRun = errors.New("storage: dry run")
+
+// schemaVersion 함수는 DB의 스키마 버전을 반환합니다.
+// 버전 키가 없더라도 기록 버킷이 있다면 예전 DB(1)로, 아무 버킷도 없다면 새 DB(0)로 봅니다.
+func schemaVersion(tx *bolt.Tx) int {
+	if b := tx.Bucket(schemaBucket); b != nil {
+		if buf := b.Get(versionKey); len(buf) == 8 {
+			return int(binary.BigEndian.Uint64(buf))
+		}
+	}
+	version := 0
+	tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		version = 1
+		return nil
+	})
+	return version
+}
+
+func putSchemaVersion(tx *bolt.Tx, version int) error {
+	b, err := tx.CreateBucketIfNotExists(schemaBucket)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(version))
+	return b.Put(versionKey, buf)
+}
+
+// checkSchema 함수는 새 DB에 현재 스키마 버전을 기록하고, 버전이 다른 DB에는 ErrSchemaVersion을 반환합니다.
+func checkSchema(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		switch version := schemaVersion(tx); version {
+		case 0:
+			return putSchemaVersion(tx, SchemaVersion)
+		case SchemaVersion:
+			return nil
+		default:
+			return fmt.Errorf("%w: found %d, want %d", ErrSchemaVersion, version, SchemaVersion)
+		}
+	})
+}
+
+func parseBucketName(name []byte) (kind string, world, typeid int, ok bool) {
+	fields := strings.Split(string(name), "-")
+	if len(fields) != 3 {
+		return "", 0, 0, false
+	}
+	var err error
+	if world, err = strconv.Atoi(fields[1]); err != nil {
+		return "", 0, 0, false
+	}
+	if typeid, err = strconv.Atoi(fields[2]); err != nil {
+		return "", 0, 0, false
+	}
+	return fields[0], world, typeid, true
+}
+
+// MigrateReport 는 마이그레이션 결과를 요약합니다.
+type MigrateReport struct {
+	From, To   int
+	Buckets    int // 처리한 서버, 카테고리 수
+	Rekeyed    int // 소문자로 바뀐 키 수
+	Merged     int // 대소문자만 다른 키가 하나로 합쳐진 수
+	MaxUpdated int // 다시 계산되어 바뀐 최고 기록 수
+	Identities int // 새로 부여한 캐릭터 ID 수
+}
+
+func (r MigrateReport) String() string {
+	return fmt.Sprintf("schema %d -> %d: %d buckets, %d keys rekeyed, %d keys merged, %d max records recomputed, %d character ids assigned",
+		r.From, r.To, r.Buckets, r.Rekeyed, r.Merged, r.MaxUpdated, r.Identities)
+}
+
+// Migrate 함수는 path의 DB를 현재 스키마 버전으로 갱신합니다.
+// 모든 작업은 하나의 트랜잭션에서 이루어지므로 도중에 실패하면 DB는 바뀌지 않습니다.
+// dryRun이 참이면 결과만 보고하고 트랜잭션을 되돌립니다.
+func Migrate(path string, dryRun bool) (MigrateReport, error) {
+	var report MigrateReport
+	// 서버가 DB를 열고 있다면 파일 잠금을 기다리지 않고 실패합니다.
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return report, err
+	}
+	defer db.Close()
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		report.From, report.To = schemaVersion(tx), SchemaVersion
+		if report.From > SchemaVersion {
+			return fmt.Errorf("%w: found %d, want %d", ErrSchemaVersion, report.From, SchemaVersion)
+		}
+
+		type target struct{ world, typeid int }
+		targets := map[target]bool{}
+		if err := tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			if kind, world, typeid, ok := parseBucketName(name); ok && kind != kindMeta {
+				targets[target{world, typeid}] = true
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for t := range targets {
+			if report.From == SchemaVersion {
+				break
+			}
+			if report.From < 2 {
+				if err := migrateBuckets(tx, t.world, t.typeid, &report); err != nil {
+					return fmt.Errorf("%d-%d: %v", t.world, t.typeid, err)
+				}
+			}
+			if report.From < 3 {
+				if err := assignIDs(tx, t.world, t.typeid, &report); err != nil {
+					return fmt.Errorf("%d-%d: %v", t.world, t.typeid, err)
+				}
+			}
+			report.Buckets++
+		}
+
+		if err := putSchemaVersion(tx, SchemaVersion); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err == errDryRun {
+		err = nil
+	}
+	return report, err
+}
+
+// migrateBuckets 함수는 한 서버, 카테고리의 recent, history 버킷 키를 소문자로 바꾸고,
+// maxrecord 버킷을 저장된 모든 기록 중 가장 좋은 기록으로 다시 만듭니다.
+func migrateBuckets(tx *bolt.Tx, world, typeid int, report *MigrateReport) error {
+	// recent: 대소문자만 다른 키가 여럿이면 가장 나중에 수집된 기록을 남깁니다.
+	recent := map[string]Record{}
+	if err := readRecords(tx, bucketName(kindRecent, world, typeid), func(k []byte, r Record) {
+		key := string(nameKey(string(k)))
+		if key != string(k) {
+			report.Rekeyed++
+		}
+		if old, ok := recent[key]; ok {
+			report.Merged++
+			if old.CheckedTimeUnix > r.CheckedTimeUnix {
+				return
+			}
+		}
+		recent[key] = r
+	}); err != nil {
+		return err
+	}
+
+	// history: 같은 주의 기록이 겹치면 더 좋은 기록을 남깁니다.
+	history := map[string]map[string]Record{}
+	if bh := tx.Bucket(bucketName(kindHistory, world, typeid)); bh != nil {
+		if err := bh.ForEach(func(k, v []byte) error {
+			bc := bh.Bucket(k)
+			if bc == nil {
+				return nil
+			}
+			key := string(nameKey(string(k)))
+			if key != string(k) {
+				report.Rekeyed++
+			}
+			weeks, ok := history[key]
+			if !ok {
+				weeks = map[string]Record{}
+				history[key] = weeks
+			} else {
+				report.Merged++
+			}
+			return bc.ForEach(func(wk, v []byte) error {
+				var r Record
+				if err := json.Unmarshal(v, &r); err != nil {
+					return err
+				}
+				if old, ok := weeks[string(wk)]; !ok || Better(r, old) {
+					weeks[string(wk)] = r
+				}
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+	}
+
+	// maxrecord: 기존 최고 기록, 최근 기록, 주별 기록을 모두 비교합니다.
+	maxes := map[string]Record{}
+	if err := readRecords(tx, bucketName(kindMax, world, typeid), func(k []byte, r Record) {
+		key := string(nameKey(string(k)))
+		if key != string(k) {
+			report.Rekeyed++
+		}
+		if old, ok := maxes[key]; ok {
+			report.Merged++
+			if !Better(r, old) {
+				return
+			}
+		}
+		maxes[key] = r
+	}); err != nil {
+		return err
+	}
+	// 예전 DB는 대소문자가 섞인 닉네임의 최고 기록을 최근 기록으로 덮어썼으므로 최근 기록도 함께 비교합니다.
+	updated := map[string]bool{}
+	for key, r := range recent {
+		if old, ok := maxes[key]; !ok || Better(r, old) {
+			maxes[key] = r
+			updated[key] = true
+		}
+	}
+	for key, weeks := range history {
+		for _, r := range weeks {
+			if old, ok := maxes[key]; !ok || Better(r, old) {
+				maxes[key] = r
+				updated[key] = true
+			}
+		}
+	}
+	report.MaxUpdated += len(updated)
+
+	if err := rewriteRecords(tx, bucketName(kindRecent, world, typeid), recent); err != nil {
+		return err
+	}
+	if err := rewriteRecords(tx, bucketName(kindMax, world, typeid), maxes); err != nil {
+		return err
+	}
+
+	return rewriteHistory(tx, bucketName(kindHistory, world, typeid), history)
+}
+
+// assignIDs 함수는 소문자 닉네임을 키로 쓰던 버킷에서 닉네임마다 캐릭터 ID를 부여하고,
+// recent, maxrecord, history 버킷의 키를 ID로 바꿉니다.
+func assignIDs(tx *bolt.Tx, world, typeid int, report *MigrateReport) error {
+	recent, maxes := map[string]Record{}, map[string]Record{}
+	if err := readRecords(tx, bucketName(kindRecent, world, typeid), func(k []byte, r Record) {
+		recent[string(k)] = r
+	}); err != nil {
+		return err
+	}
+	if err := readRecords(tx, bucketName(kindMax, world, typeid), func(k []byte, r Record) {
+		maxes[string(k)] = r
+	}); err != nil {
+		return err
+	}
+	history := map[string]map[string]Record{}
+	if bh := tx.Bucket(bucketName(kindHistory, world, typeid)); bh != nil {
+		if err := bh.ForEach(func(k, v []byte) error {
+			bc := bh.Bucket(k)
+			if bc == nil {
+				return nil
+			}
+			weeks := map[string]Record{}
+			history[string(k)] = weeks
+			return bc.ForEach(func(wk, v []byte) error {
+				var r Record
+				if err := json.Unmarshal(v, &r); err != nil {
+					return err
+				}
+				weeks[string(wk)] = r
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+	}
+
+	names := map[string]bool{}
+	for key := range recent {
+		names[key] = true
+	}
+	for key := range maxes {
+		names[key] = true
+	}
+	for key := range history {
+		names[key] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for key := range names {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	bc, err := tx.CreateBucketIfNotExists(charactersBucket)
+	if err != nil {
+		return err
+	}
+	bn, err := tx.CreateBucketIfNotExists(bucketName(kindNames, world, typeid))
+	if err != nil {
+		return err
+	}
+	newRecent, newMax := map[string]Record{}, map[string]Record{}
+	newHistory := map[string]map[string]Record{}
+	for _, key := range sorted {
+		id, err := bc.NextSequence()
+		if err != nil {
+			return err
+		}
+		ik := string(idKey(id))
+
+		// 가장 최근에 수집된 기록으로 캐릭터 정보를 만듭니다.
+		var last Record
+		var found bool
+		if r, ok := recent[key]; ok {
+			last, found = r, true
+		}
+		for _, r := range history[key] {
+			if !found || r.CheckedTimeUnix > last.CheckedTimeUnix {
+				last, found = r, true
+			}
+		}
+		if !found {
+			last = maxes[key]
+		}
+		c := &Identity{ID: id, Type: typeid}
+		c.update(world, last, time.Unix(last.CheckedTimeUnix, 0))
+		if err := putIdentity(bc, c); err != nil {
+			return err
+		}
+		if err := bn.Put([]byte(key), idKey(id)); err != nil {
+			return err
+		}
+		report.Identities++
+
+		if r, ok := recent[key]; ok {
+			newRecent[ik] = r
+		}
+		if r, ok := maxes[key]; ok {
+			newMax[ik] = r
+		}
+		if weeks, ok := history[key]; ok {
+			newHistory[ik] = weeks
+		}
+	}
+
+	if err := rewriteRecords(tx, bucketName(kindRecent, world, typeid), newRecent); err != nil {
+		return err
+	}
+	if err := rewriteRecords(tx, bucketName(kindMax, world, typeid), newMax); err != nil {
+		return err
+	}
+	return rewriteHistory(tx, bucketName(kindHistory, world, typeid), newHistory)
+}
+
+func rewriteHistory(tx *bolt.Tx, name []byte, history map[string]map[string]Record) error {
+	if tx.Bucket(name) != nil {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+	}
+	bh, err := tx.CreateBucket(name)
+	if err != nil {
+		return err
+	}
+	for key, weeks := range history {
+		bc, err := bh.CreateBucket([]byte(key))
+		if err != nil {
+			return err
+		}
+		for wk, r := range weeks {
+			buf, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+			if err := bc.Put([]byte(wk), buf); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readRecords(tx *bolt.Tx, name []byte, fn func(k []byte, r Record)) error {
+	b := tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(k, v []byte) error {
+		var r Record
+		if err := json.Unmarshal(v, &r); err != nil {
+			return fmt.Errorf("%s/%s: %v", name, k, err)
+		}
+		fn(k, r)
+		return nil
+	})
+}
+
+func rewriteRecords(tx *bolt.Tx, name []byte, records map[string]Record) error {
+	if tx.Bucket(name) != nil {
+		if err := tx.DeleteBucket(name); err != nil {
+			return err
+		}
+	}
+	b, err := tx.CreateBucket(name)
+	if err != nil {
+		return err
+	}
+	for key, r := range records {
+		buf, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte(key), buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
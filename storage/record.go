@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Record 는 랭킹 페이지의 한 항목이며, DB에 JSON으로 저장되는 형식이기도 합니다.
+type Record struct {
+	Rank       int64  `json:"rank,string"`
+	Move       int64  `json:"move,string"`
+	IconURL    string `json:"icon"`
+	Name       string `json:"nick"`
+	Job        string `json:"job"`
+	DetailJob  string `json:"detail_job"`
+	Level      int64  `json:"level"`
+	Exp        int64  `json:"exp"`
+	Popularity int64  `json:"popular"`
+	FloorStr   string `json:"floor"`
+	Duration   string `json:"duration"`
+	GuildID    int64  `json:"guild_worldid,string"` // ?
+
+	Second          int   `json:"second,omitempty"`
+	Minute          int   `json:"minute,omitempty"`
+	World           int   `json:"world,omitempty"`
+	Floor           int   `json:"rawfloor,omitempty"`
+	Type            int   `json:"type,omitempty"`
+	CheckedTimeUnix int64 `json:"checkedtime,omitempty"`
+}
+
+// FullSec 함수는 소요 시간을 초 단위로 반환합니다.
+func (r *Record) FullSec() int {
+	return r.Second + r.Minute*60
+}
+
+// Better 함수는 a가 b보다 좋은 기록인지 반환합니다.
+// 높은 층이 우선이며, 같은 층이라면 소요 시간이 짧은 쪽이 우선입니다.
+func Better(a, b Record) bool {
+	return a.Floor > b.Floor || (a.Floor == b.Floor && a.FullSec() < b.FullSec())
+}
+
+// WeekAlign 은 수집 시각을 기록의 달성 시각으로 변환합니다.
+// 변환된 시각의 ISO 주가 곧 기록이 속한 주입니다.
+type WeekAlign func(time.Time) time.Time
+
+// ThisWeek 는 이번 주 랭킹에 사용합니다. 월요일이 아니라면 하루 전 날짜를 달성 시각으로 봅니다.
+// 월요일에 수집한 기록은 새 주의 기록이므로 그대로 둡니다.
+func ThisWeek(t time.Time) time.Time {
+	if t.Weekday() != time.Monday {
+		return t.AddDate(0, 0, -1)
+	}
+	return t
+}
+
+// LastWeek 는 지난주 랭킹에 사용하며, 해당 날짜에서 전 주의 일요일을 반환합니다.
+// 시, 분, 초는 입력과 같습니다. 각 주는 월요일이 시작입니다.
+func LastWeek(t time.Time) time.Time {
+	weekday := t.Weekday()
+	if weekday == time.Sunday {
+		return t.AddDate(0, 0, -7)
+	}
+	return t.AddDate(0, 0, -int(weekday))
+}
+
+// WeekKey 함수는 주어진 시각이 속한 ISO 주를 정렬 가능한 문자열로 반환합니다. (예: 2018-W09)
+func WeekKey(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+var kst = time.FixedZone("KST", 9*60*60)
+
+func TestWeekAlign(t *testing.T) {
+	sundayNight := time.Date(2018, 3, 11, 23, 59, 59, 0, kst) // 일요일 자정 직전
+	mondayMidnight := time.Date(2018, 3, 12, 0, 0, 0, 0, kst) // 월요일 자정
+	mondayMorning := time.Date(2018, 3, 12, 7, 0, 0, 0, kst)
+
+	tests := []struct {
+		name  string
+		align WeekAlign
+		in    time.Time
+		want  time.Time
+		week  string
+	}{
+		{"ThisWeek before midnight", ThisWeek, sundayNight, time.Date(2018, 3, 10, 23, 59, 59, 0, kst), "2018-W10"},
+		{"ThisWeek at midnight", ThisWeek, mondayMidnight, mondayMidnight, "2018-W11"},
+		{"ThisWeek monday crawl", ThisWeek, mondayMorning, mondayMorning, "2018-W11"},
+		{"LastWeek before midnight", LastWeek, sundayNight, time.Date(2018, 3, 4, 23, 59, 59, 0, kst), "2018-W09"},
+		{"LastWeek at midnight", LastWeek, mondayMidnight, time.Date(2018, 3, 11, 0, 0, 0, 0, kst), "2018-W10"},
+		{"LastWeek monday crawl", LastWeek, mondayMorning, time.Date(2018, 3, 11, 7, 0, 0, 0, kst), "2018-W10"},
+	}
+	for _, tt := range tests {
+		got := tt.align(tt.in)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		if week := WeekKey(got); week != tt.week {
+			t.Errorf("%s: week = %s, want %s", tt.name, week, tt.week)
+		}
+	}
+}
+
+func TestWeekKey(t *testing.T) {
+	tests := []struct {
+		in   time.Time
+		want string
+	}{
+		{time.Date(2020, 12, 27, 12, 0, 0, 0, kst), "2020-W52"}, // 일요일
+		{time.Date(2020, 12, 28, 0, 0, 0, 0, kst), "2020-W53"},
+		{time.Date(2021, 1, 1, 12, 0, 0, 0, kst), "2020-W53"},
+		{time.Date(2021, 1, 3, 23, 59, 59, 0, kst), "2020-W53"},
+		{time.Date(2021, 1, 4, 0, 0, 0, 0, kst), "2021-W01"},
+		{time.Date(2018, 12, 31, 0, 0, 0, 0, kst), "2019-W01"},
+		{time.Date(2018, 3, 5, 0, 0, 0, 0, kst), "2018-W10"},
+	}
+	for _, tt := range tests {
+		if got := WeekKey(tt.in); got != tt.want {
+			t.Errorf("WeekKey(%v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	// 정렬 가능한 형식이어야 주별 기록의 커서 순서가 시간 순서가 됩니다.
+	if WeekKey(time.Date(2020, 12, 31, 0, 0, 0, 0, kst)) >= WeekKey(time.Date(2021, 1, 4, 0, 0, 0, 0, kst)) {
+		t.Error("week keys do not sort across the year boundary")
+	}
+}
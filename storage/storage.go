@@ -0,0 +1,357 @@
+// Package storage 는 수집한 무릉도장 기록을 boltDB에 저장하고 조회합니다.
+// 버킷 구성은 이 패키지 밖으로 드러나지 않습니다.
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+// 각 서버, 카테고리마다 아래 종류의 버킷이 하나씩 있습니다. (예: recent-1-2)
+// 닉네임 색인을 제외한 버킷은 캐릭터 ID를 키로 사용합니다.
+const (
+	kindRecent  = "recent"    // 캐릭터별 최근 기록
+	kindMax     = "maxrecord" // 캐릭터별 최고 기록
+	kindMeta    = "metadata"  // 수집 기간 (start, end)
+	kindHistory = "history"   // 캐릭터별 하위 버킷 아래 주별 기록
+	kindNames   = "names"     // 소문자 현재 닉네임 -> 캐릭터 ID
+)
+
+func bucketName(kind string, world, typeid int) []byte {
+	return []byte(kind + "-" + strconv.Itoa(world) + "-" + strconv.Itoa(typeid))
+}
+
+func nameKey(name string) []byte {
+	return []byte(strings.ToLower(name))
+}
+
+func putUnix(b *bolt.Bucket, key string, t time.Time) error {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(t.Unix()))
+	return b.Put([]byte(key), buf)
+}
+
+func getUnix(b *bolt.Bucket, key string) int64 {
+	buf := b.Get([]byte(key))
+	if len(buf) != 8 {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(buf))
+}
+
+// Store 는 기록 DB입니다.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open 함수는 path의 DB 파일을 엽니다. 파일이 없으면 새로 만듭니다.
+// 스키마 버전이 SchemaVersion과 다르면 ErrSchemaVersion 오류를 반환하며, 이 경우 Migrate가 필요합니다.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close 함수는 DB 파일을 닫습니다.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// PutWeekResults 함수는 한 서버, 카테고리의 랭킹 수집 결과를 저장합니다.
+// 각 기록의 층수와 소요 시간은 미리 파싱되어 있어야 하며, 달성 시각은 crawledAt을 align으로 변환한 값이 됩니다.
+// 닉네임을 바꿨거나 서버를 옮긴 캐릭터는 예전 기록에 이어서 저장됩니다.
+func (s *Store) PutWeekResults(world, typeid int, records []Record, crawledAt time.Time, align WeekAlign) error {
+	realTime := align(crawledAt)
+	week := []byte(WeekKey(realTime))
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		br, err := tx.CreateBucketIfNotExists(bucketName(kindRecent, world, typeid))
+		if err != nil {
+			return err
+		}
+
+		bm, err := tx.CreateBucketIfNotExists(bucketName(kindMax, world, typeid))
+		if err != nil {
+			return err
+		}
+
+		bmeta, err := tx.CreateBucketIfNotExists(bucketName(kindMeta, world, typeid))
+		if err != nil {
+			return err
+		}
+
+		bh, err := tx.CreateBucketIfNotExists(bucketName(kindHistory, world, typeid))
+		if err != nil {
+			return err
+		}
+
+		ids, err := resolveIdentities(tx, world, typeid, records, realTime)
+		if err != nil {
+			return err
+		}
+
+		for i, r := range records {
+			r.CheckedTimeUnix = realTime.Unix()
+			key := idKey(ids[i])
+
+			buf, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+
+			// 같은 주의 기록은 가장 최근에 수집된 것으로 덮어씁니다.
+			bc, err := bh.CreateBucketIfNotExists(key)
+			if err != nil {
+				return err
+			}
+			if err := bc.Put(week, buf); err != nil {
+				return err
+			}
+
+			mbuf := bm.Get(key)
+			if mbuf == nil {
+				if err := bm.Put(key, buf); err != nil {
+					return err
+				}
+				if err := br.Put(key, buf); err != nil {
+					return err
+				}
+				continue
+			}
+
+			var mrank Record
+			if err := json.Unmarshal(mbuf, &mrank); err != nil {
+				return err
+			}
+
+			rbuf := br.Get(key)
+			if rbuf != nil {
+				var rrank Record
+				if err := json.Unmarshal(rbuf, &rrank); err != nil {
+					return err
+				}
+
+				if WeekKey(time.Unix(rrank.CheckedTimeUnix, 0)) == string(week) &&
+					rrank.Floor == r.Floor && rrank.FullSec() == r.FullSec() {
+					continue
+				}
+			}
+
+			if err := br.Put(key, buf); err != nil {
+				return err
+			}
+
+			if Better(r, mrank) {
+				if err := bm.Put(key, buf); err != nil {
+					return err
+				}
+			}
+		}
+
+		if bmeta.Get([]byte("start")) == nil {
+			if err := putUnix(bmeta, "start", crawledAt); err != nil {
+				return err
+			}
+		}
+		return putUnix(bmeta, "end", crawledAt)
+	})
+}
+
+// Character 는 한 캐릭터의 최근 기록과 최고 기록입니다.
+type Character struct {
+	Identity
+	Recent Record
+	Max    Record
+}
+
+// lookup 함수는 현재 닉네임의 캐릭터 ID를 반환합니다. 없으면 nil을 반환합니다.
+func lookup(tx *bolt.Tx, world, typeid int, name string) []byte {
+	bn := tx.Bucket(bucketName(kindNames, world, typeid))
+	if bn == nil {
+		return nil
+	}
+	return bn.Get(nameKey(name))
+}
+
+// GetCharacter 함수는 현재 닉네임으로 캐릭터의 기록을 반환합니다. 기록이 없으면 nil을 반환합니다.
+func (s *Store) GetCharacter(world, typeid int, name string) (*Character, error) {
+	var ret *Character
+	err := s.db.View(func(tx *bolt.Tx) error {
+		br, bm := tx.Bucket(bucketName(kindRecent, world, typeid)), tx.Bucket(bucketName(kindMax, world, typeid))
+		bc := tx.Bucket(charactersBucket)
+		id := lookup(tx, world, typeid, name)
+		if br == nil || bm == nil || bc == nil || id == nil {
+			return nil
+		}
+
+		rbuf, mbuf := br.Get(id), bm.Get(id)
+		if rbuf == nil || mbuf == nil {
+			return nil
+		}
+
+		var c Character
+		ident, err := getIdentity(bc, id)
+		if err != nil {
+			return err
+		}
+		if ident != nil {
+			c.Identity = *ident
+		}
+		if err := json.Unmarshal(rbuf, &c.Recent); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(mbuf, &c.Max); err != nil {
+			return err
+		}
+		ret = &c
+		return nil
+	})
+	return ret, err
+}
+
+// History 함수는 현재 닉네임으로 캐릭터의 주별 기록을 오래된 순서로 반환합니다.
+// 닉네임을 바꾸기 전의 기록도 포함되며, 각 기록에는 당시 닉네임이 남아 있습니다.
+func (s *Store) History(world, typeid int, name string) ([]Record, error) {
+	var ret []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bh := tx.Bucket(bucketName(kindHistory, world, typeid))
+		id := lookup(tx, world, typeid, name)
+		if bh == nil || id == nil {
+			return nil
+		}
+		bc := bh.Bucket(id)
+		if bc == nil {
+			return nil
+		}
+
+		// 키가 연도-주 형식이므로 커서 순서가 곧 시간 순서입니다.
+		return bc.ForEach(func(k, v []byte) error {
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			ret = append(ret, r)
+			return nil
+		})
+	})
+	return ret, err
+}
+
+// Meta 는 한 서버, 카테고리의 수집 기간입니다. 각 값은 유닉스 시각이며, 수집한 적이 없으면 0입니다.
+type Meta struct {
+	Start int64
+	End   int64
+}
+
+// Meta 함수는 한 서버, 카테고리의 수집 기간을 반환합니다.
+func (s *Store) Meta(world, typeid int) (Meta, error) {
+	var ret Meta
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bmeta := tx.Bucket(bucketName(kindMeta, world, typeid))
+		if bmeta == nil {
+			return nil
+		}
+		ret.Start, ret.End = getUnix(bmeta, "start"), getUnix(bmeta, "end")
+		return nil
+	})
+	return ret, err
+}
+
+// ForEachMax 함수는 한 서버, 카테고리에서 현재 닉네임이 있는 모든 캐릭터의 최고 기록에 대해 fn을 호출합니다.
+func (s *Store) ForEachMax(world, typeid int, fn func(Record) error) error {
+	return s.SeekMax(world, typeid, "", fn)
+}
+
+// SeekMax 함수는 소문자 현재 닉네임이 prefix로 시작하는 캐릭터의 최고 기록에 대해 fn을 호출합니다.
+func (s *Store) SeekMax(world, typeid int, prefix string, fn func(Record) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		bm, bn := tx.Bucket(bucketName(kindMax, world, typeid)), tx.Bucket(bucketName(kindNames, world, typeid))
+		if bm == nil || bn == nil {
+			return nil
+		}
+		p := nameKey(prefix)
+		c := bn.Cursor()
+		for k, id := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, id = c.Next() {
+			v := bm.Get(id)
+			if v == nil {
+				continue
+			}
+			var r Record
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ForEachWeek 함수는 week(WeekKey 형식) 주에 기록이 있는 모든 캐릭터에 대해 그 주의 기록으로 fn을 호출합니다.
+func (s *Store) ForEachWeek(world, typeid int, week string, fn func(Record) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		bh := tx.Bucket(bucketName(kindHistory, world, typeid))
+		if bh == nil {
+			return nil
+		}
+		return bh.ForEach(func(k, v []byte) error {
+			bc := bh.Bucket(k)
+			if bc == nil {
+				return nil
+			}
+			buf := bc.Get([]byte(week))
+			if buf == nil {
+				return nil
+			}
+			var r Record
+			if err := json.Unmarshal(buf, &r); err != nil {
+				return err
+			}
+			return fn(r)
+		})
+	})
+}
+
+// Sizes 는 한 서버, 카테고리에 저장된 캐릭터 수입니다.
+type Sizes struct {
+	Recent  int
+	Max     int
+	History int
+}
+
+// Sizes 함수는 한 서버, 카테고리에 저장된 캐릭터 수를 반환합니다.
+func (s *Store) Sizes(world, typeid int) (Sizes, error) {
+	var ret Sizes
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(bucketName(kindRecent, world, typeid)); b != nil {
+			ret.Recent = b.Stats().KeyN
+		}
+		if b := tx.Bucket(bucketName(kindMax, world, typeid)); b != nil {
+			ret.Max = b.Stats().KeyN
+		}
+		if b := tx.Bucket(bucketName(kindHistory, world, typeid)); b != nil {
+			// 하위 버킷마다 값이 nil인 키가 하나씩 있습니다.
+			return b.ForEach(func(k, v []byte) error {
+				if v == nil {
+					ret.History++
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return ret, err
+}
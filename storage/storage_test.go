@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func testRecord(name string, floor, minute, second int) Record {
+	return Record{
+		Name: name, Job: "전사", DetailJob: "히어로", Level: 235, Exp: 1000, IconURL: "icon-" + name,
+		Floor: floor, Minute: minute, Second: second,
+	}
+}
+
+func TestPutWeekResultsRoundTrip(t *testing.T) {
+	s := openTestStore(t)
+	week1 := time.Date(2018, 3, 7, 7, 0, 0, 0, kst)
+	week2 := week1.AddDate(0, 0, 7)
+
+	if err := s.PutWeekResults(1, 2, []Record{testRecord("MixedCase", 50, 10, 0), testRecord("other", 40, 5, 0)}, week1, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutWeekResults(1, 2, []Record{testRecord("MixedCase", 45, 9, 0)}, week2, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"MixedCase", "mixedcase", "MIXEDCASE"} {
+		c, err := s.GetCharacter(1, 2, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c == nil {
+			t.Fatalf("GetCharacter(%q) = nil", name)
+		}
+		if c.Name != "MixedCase" || c.Max.Floor != 50 || c.Recent.Floor != 45 {
+			t.Errorf("GetCharacter(%q) = %s, max %d, recent %d, want MixedCase, 50, 45", name, c.Name, c.Max.Floor, c.Recent.Floor)
+		}
+		if got, want := c.Recent.CheckedTimeUnix, ThisWeek(week2).Unix(); got != want {
+			t.Errorf("recent checked time = %d, want %d", got, want)
+		}
+	}
+
+	history, err := s.History(1, 2, "mixedcase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 || history[0].Floor != 50 || history[1].Floor != 45 {
+		t.Fatalf("history = %+v, want floors 50, 45", history)
+	}
+	if WeekKey(time.Unix(history[0].CheckedTimeUnix, 0).In(kst)) != "2018-W10" {
+		t.Errorf("first week = %s", WeekKey(time.Unix(history[0].CheckedTimeUnix, 0).In(kst)))
+	}
+
+	// 다른 서버, 카테고리와 없는 닉네임은 찾지 않습니다.
+	for _, q := range []struct{ world, typeid int }{{12, 2}, {1, 1}} {
+		if c, err := s.GetCharacter(q.world, q.typeid, "MixedCase"); err != nil || c != nil {
+			t.Errorf("GetCharacter(%d, %d) = %v, %v, want nil", q.world, q.typeid, c, err)
+		}
+	}
+	if c, err := s.GetCharacter(1, 2, "nobody"); err != nil || c != nil {
+		t.Errorf("GetCharacter(nobody) = %v, %v, want nil", c, err)
+	}
+
+	meta, err := s.Meta(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Start != week1.Unix() || meta.End != week2.Unix() {
+		t.Errorf("meta = %+v, want %d ~ %d", meta, week1.Unix(), week2.Unix())
+	}
+}
+
+func TestPutWeekResultsSameWeek(t *testing.T) {
+	s := openTestStore(t)
+	crawled := time.Date(2018, 3, 7, 7, 0, 0, 0, kst)
+
+	if err := s.PutWeekResults(1, 2, []Record{testRecord("a", 40, 5, 0)}, crawled, ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	// 같은 주에 다시 수집하면 주별 기록은 덮어쓰고 최고 기록만 갱신합니다.
+	if err := s.PutWeekResults(1, 2, []Record{testRecord("a", 42, 6, 0)}, crawled.AddDate(0, 0, 1), ThisWeek); err != nil {
+		t.Fatal(err)
+	}
+	history, err := s.History(1, 2, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Floor != 42 {
+		t.Errorf("history = %+v, want a single week at floor 42", history)
+	}
+}